@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"golang.org/x/tools/txtar"
+)
+
+// WriteTxtar writes files to w as a txtar archive.
+func WriteTxtar(w io.Writer, files []File) error {
+	archive := &txtar.Archive{Files: make([]txtar.File, 0, len(files))}
+	for _, f := range files {
+		archive.Files = append(archive.Files, txtar.File{
+			Name: filepath.ToSlash(f.Path),
+			Data: f.Data,
+		})
+	}
+
+	if _, err := w.Write(txtar.Format(archive)); err != nil {
+		return fmt.Errorf("error writing txtar archive: %w", err)
+	}
+
+	return nil
+}
+
+// WriteTar writes files to w as a tar archive.
+func WriteTar(w io.Writer, files []File) error {
+	tw := tar.NewWriter(w)
+
+	// Archives are reproducible, so they don't carry the time of generation.
+	modTime := time.Unix(0, 0)
+
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    filepath.ToSlash(f.Path),
+			Mode:    0o644,
+			Size:    int64(len(f.Data)),
+			ModTime: modTime,
+		}); err != nil {
+			return fmt.Errorf("error writing tar archive: %w", err)
+		}
+
+		if _, err := tw.Write(f.Data); err != nil {
+			return fmt.Errorf("error writing tar archive: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error writing tar archive: %w", err)
+	}
+
+	return nil
+}
+
+// MergeGoFiles merges Go source files of the same package into a single file.
+// The imports of all files are combined, and the comments before the package
+// clause are taken from the first file.
+func MergeGoFiles(files []File) ([]byte, error) {
+	var (
+		pkg     string
+		header  []byte
+		imports = make(map[string]bool)
+		bodies  [][]byte
+	)
+
+	fset := token.NewFileSet()
+
+	for _, f := range files {
+		file, err := parser.ParseFile(fset, f.Path, f.Data, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", f.Path, err)
+		}
+
+		switch {
+		case pkg == "":
+			pkg = file.Name.Name
+			header = f.Data[:fset.Position(file.Package).Offset]
+		case pkg != file.Name.Name:
+			return nil, fmt.Errorf("%s is in package %s, not %s",
+				f.Path, file.Name.Name, pkg)
+		}
+
+		// The body starts after the package clause and the imports.
+		end := file.Name.End()
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.IMPORT {
+				break
+			}
+
+			end = gen.End()
+		}
+
+		for _, spec := range file.Imports {
+			imp := spec.Path.Value
+			if spec.Name != nil {
+				imp = spec.Name.Name + " " + imp
+			}
+
+			imports[imp] = true
+		}
+
+		bodies = append(bodies, f.Data[fset.Position(end).Offset:])
+	}
+
+	var buf bytes.Buffer
+
+	buf.Write(header)
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+
+	if len(imports) > 0 {
+		sorted := make([]string, 0, len(imports))
+		for imp := range imports {
+			sorted = append(sorted, imp)
+		}
+
+		sort.Strings(sorted)
+
+		buf.WriteString("\nimport (\n")
+
+		for _, imp := range sorted {
+			fmt.Fprintf(&buf, "\t%s\n", imp)
+		}
+
+		buf.WriteString(")\n")
+	}
+
+	for _, body := range bodies {
+		buf.WriteString("\n")
+		buf.Write(body)
+	}
+
+	return FormatGoCode(buf.Bytes())
+}
@@ -0,0 +1,110 @@
+package internal_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"golang.org/x/tools/txtar"
+)
+
+var bundleFiles = []internal.File{
+	{Path: "a.go", Data: []byte("package p\n\nimport \"fmt\"\n\n// A prints.\nfunc A() { fmt.Println() }\n")},
+	{Path: "docs/b.md", Data: []byte("# B\n")},
+}
+
+// TestWriteTxtar tests that files round-trip through a txtar archive.
+func TestWriteTxtar(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := internal.WriteTxtar(&buf, bundleFiles); err != nil {
+		t.Fatalf("WriteTxtar() error = %v", err)
+	}
+
+	var got []internal.File
+	for _, f := range txtar.Parse(buf.Bytes()).Files {
+		got = append(got, internal.File{Path: f.Name, Data: f.Data})
+	}
+
+	if !reflect.DeepEqual(got, bundleFiles) {
+		t.Errorf("files = %+v, want %+v", got, bundleFiles)
+	}
+}
+
+// TestWriteTar tests that files round-trip through a tar archive.
+func TestWriteTar(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := internal.WriteTar(&buf, bundleFiles); err != nil {
+		t.Fatalf("WriteTar() error = %v", err)
+	}
+
+	var got []internal.File
+
+	tr := tar.NewReader(&buf)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, internal.File{Path: hdr.Name, Data: data})
+	}
+
+	if !reflect.DeepEqual(got, bundleFiles) {
+		t.Errorf("files = %+v, want %+v", got, bundleFiles)
+	}
+}
+
+// TestMergeGoFiles tests that the imports and declarations of several files
+// are merged into one.
+func TestMergeGoFiles(t *testing.T) {
+	t.Parallel()
+
+	got, err := internal.MergeGoFiles([]internal.File{
+		bundleFiles[0],
+		{Path: "b.go", Data: []byte("package p\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc B() { fmt.Fprintln(os.Stderr) }\n")},
+	})
+	if err != nil {
+		t.Fatalf("MergeGoFiles() error = %v", err)
+	}
+
+	want := `package p
+
+import (
+	"fmt"
+	"os"
+)
+
+// A prints.
+func A() { fmt.Println() }
+
+func B() { fmt.Fprintln(os.Stderr) }
+`
+	if string(got) != want {
+		t.Errorf("MergeGoFiles() = \n%s\nwant\n%s", got, want)
+	}
+
+	_, err = internal.MergeGoFiles([]internal.File{
+		bundleFiles[0],
+		{Path: "q.go", Data: []byte("package q\n")},
+	})
+	if err == nil {
+		t.Error("MergeGoFiles() of two packages error = nil")
+	}
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity is the severity of a Diagnostic.
+type Severity int
+
+// Known severities.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// String returns the lowercase name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Diagnostic is a single problem found in the source document.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Pointer is the JSON pointer to the offending node, e.g.
+	// #/components/schemas/Foo/properties/bar.
+	Pointer string `json:"pointer,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// String formats the diagnostic the way compilers do, e.g.
+// spec.yaml:12:9: error: unsupported type foo (#/components/schemas/Foo).
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+
+	if d.File != "" {
+		sb.WriteString(d.File)
+
+		if d.Line > 0 {
+			fmt.Fprintf(&sb, ":%d:%d", d.Line, d.Column)
+		}
+
+		sb.WriteString(": ")
+	}
+
+	fmt.Fprintf(&sb, "%s: %s", d.Severity, d.Message)
+
+	if d.Pointer != "" {
+		fmt.Fprintf(&sb, " (%s)", d.Pointer)
+	}
+
+	return sb.String()
+}
+
+// Diagnostics is a collection of Diagnostic.
+type Diagnostics []Diagnostic
+
+// AddError adds an error at the given JSON pointer.
+func (ds *Diagnostics) AddError(pointer string, msg string) {
+	*ds = append(*ds, Diagnostic{
+		Severity: SeverityError,
+		Message:  msg,
+		Pointer:  pointer,
+	})
+}
+
+// AddWarning adds a warning at the given JSON pointer.
+func (ds *Diagnostics) AddWarning(pointer string, msg string) {
+	*ds = append(*ds, Diagnostic{
+		Severity: SeverityWarning,
+		Message:  msg,
+		Pointer:  pointer,
+	})
+}
+
+// Append adds all of the given diagnostics.
+func (ds *Diagnostics) Append(other Diagnostics) {
+	*ds = append(*ds, other...)
+}
+
+// HasErrors returns true if any of the diagnostics is an error.
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Locate fills in the file, line and column of every diagnostic from the given
+// source map.
+func (ds Diagnostics) Locate(sm *SourceMap) {
+	if sm == nil {
+		return
+	}
+
+	for i := range ds {
+		if ds[i].File == "" {
+			ds[i].File = sm.File
+		}
+
+		if ds[i].Line > 0 || ds[i].Pointer == "" {
+			continue
+		}
+
+		ds[i].Line, ds[i].Column = sm.Locate(ds[i].Pointer)
+	}
+}
+
+// Error implements the error interface, joining all diagnostics one per line.
+func (ds Diagnostics) Error() string {
+	lines := make([]string, 0, len(ds))
+	for _, d := range ds {
+		lines = append(lines, d.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// WriteText writes the diagnostics in compiler-style format, one per line.
+func (ds Diagnostics) WriteText(w io.Writer) error {
+	for _, d := range ds {
+		if _, err := fmt.Fprintln(w, d.String()); err != nil {
+			return fmt.Errorf("error writing diagnostics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the diagnostics as a JSON array.
+func (ds Diagnostics) WriteJSON(w io.Writer) error {
+	if ds == nil {
+		ds = Diagnostics{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(ds); err != nil {
+		return fmt.Errorf("error writing diagnostics: %w", err)
+	}
+
+	return nil
+}
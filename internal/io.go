@@ -1,24 +1,201 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
+// File is a rendered file waiting to be written to Path.
+type File struct {
+	Path string
+	Data []byte
+	// Scaffold is true for files that are maintained by hand once written.
+	Scaffold bool
+}
+
 // WriteFileBytes writes bytes to a file path. If the file already exists, it is
-// truncated.
+// replaced atomically.
 func WriteFileBytes(path string, bytes []byte) error {
-	file, err := os.Create(path)
+	return WriteFilesAtomic([]File{{Path: path, Data: bytes}})
+}
+
+// WriteFilesAtomic writes all files as a batch. Every file is first written to
+// a temporary file next to its destination, and only once all of them have
+// been written are they renamed into place. If any step fails, files that were
+// already replaced are restored so the destination is never left half-updated.
+// Missing directories are created, and removed again if writing fails.
+func WriteFilesAtomic(files []File) error {
+	temps := make([]string, 0, len(files))
+
+	var dirs []string
+
+	cleanup := func() {
+		for _, temp := range temps {
+			os.Remove(temp)
+		}
+
+		for i := len(dirs) - 1; i >= 0; i-- {
+			os.Remove(dirs[i])
+		}
+	}
+
+	for _, f := range files {
+		created, err := createDirs(filepath.Dir(f.Path))
+		dirs = append(dirs, created...)
+
+		if err != nil {
+			cleanup()
+			return err
+		}
+	}
+
+	for _, f := range files {
+		temp, err := writeTempFile(f)
+		if err != nil {
+			cleanup()
+			return err
+		}
+
+		temps = append(temps, temp)
+	}
+
+	// backups maps a replaced destination to the file holding its previous
+	// contents, or an empty string if the destination did not exist before.
+	backups := make(map[string]string, len(files))
+	renamed := make([]string, 0, len(files))
+
+	rollback := func() {
+		for _, path := range renamed {
+			if backup := backups[path]; backup != "" {
+				os.Rename(backup, path)
+			} else {
+				os.Remove(path)
+			}
+		}
+
+		cleanup()
+	}
+
+	for i, f := range files {
+		backup, err := backupFile(f.Path)
+		if err != nil {
+			rollback()
+			return err
+		}
+
+		if err := os.Rename(temps[i], f.Path); err != nil {
+			if backup != "" {
+				os.Rename(backup, f.Path)
+			}
+
+			rollback()
+
+			return fmt.Errorf("error replacing file: %w", err)
+		}
+
+		backups[f.Path] = backup
+		renamed = append(renamed, f.Path)
+	}
+
+	for _, backup := range backups {
+		if backup != "" {
+			os.Remove(backup)
+		}
+	}
+
+	return nil
+}
+
+// createDirs creates dir and its missing parents, and returns the directories
+// it created, parents first.
+func createDirs(dir string) ([]string, error) {
+	var missing []string
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+
+		missing = append(missing, d)
+
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	created := make([]string, 0, len(missing))
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0o755); err != nil {
+			return created, fmt.Errorf("error creating directory: %w", err)
+		}
+
+		created = append(created, missing[i])
+	}
+
+	return created, nil
+}
+
+// writeTempFile writes the file's data to a temporary file in the same
+// directory as its destination and returns the temporary file's path.
+func writeTempFile(f File) (string, error) {
+	dir, base := filepath.Split(f.Path)
+	if dir == "" {
+		dir = "."
+	}
+
+	file, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating file: %w", err)
+	}
+
+	_, err = file.Write(f.Data)
+	if err == nil {
+		err = file.Chmod(0o644)
+	}
+
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
 	if err != nil {
-		return fmt.Errorf("error creating file: %w", err)
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error writing file: %w", err)
 	}
 
-	defer file.Close()
+	return file.Name(), nil
+}
 
-	_, err = file.Write(bytes)
+// backupFile moves an existing file out of the way so it can be restored if a
+// batch write fails. It returns an empty string if there is nothing to back up.
+func backupFile(path string) (string, error) {
+	if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	file, err := os.CreateTemp(dir, "."+base+".bak-*")
 	if err != nil {
-		return fmt.Errorf("error writing file: %w", err)
+		return "", fmt.Errorf("error creating backup file: %w", err)
 	}
 
-	return nil
+	backup := file.Name()
+	file.Close()
+
+	if err := os.Rename(path, backup); err != nil {
+		os.Remove(backup)
+		return "", fmt.Errorf("error backing up file: %w", err)
+	}
+
+	return backup, nil
 }
@@ -0,0 +1,99 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/internal"
+)
+
+// TestWriteFilesAtomic tests that all files are written as a batch.
+func TestWriteFilesAtomic(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	c := filepath.Join(dir, "pkg", "sub", "c.go")
+
+	if err := os.WriteFile(a, []byte("old a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := internal.WriteFilesAtomic([]internal.File{
+		{Path: a, Data: []byte("new a")},
+		{Path: b, Data: []byte("new b")},
+		{Path: c, Data: []byte("new c")},
+	})
+	if err != nil {
+		t.Fatalf("WriteFilesAtomic() error = %v", err)
+	}
+
+	for path, want := range map[string]string{
+		a: "new a",
+		b: "new b",
+		c: "new c",
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 3 {
+		t.Errorf("expected no leftover files or directories, got %d entries",
+			len(entries))
+	}
+}
+
+// TestWriteFilesAtomic_Failure tests that a failed batch leaves existing files
+// untouched, and removes the directories it created.
+func TestWriteFilesAtomic_Failure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+
+	if err := os.WriteFile(a, []byte("old a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := internal.WriteFilesAtomic([]internal.File{
+		{Path: a, Data: []byte("new a")},
+		{Path: filepath.Join(dir, "pkg", "c.go"), Data: []byte("new c")},
+		// a.go is not a directory.
+		{Path: filepath.Join(a, "b.go"), Data: []byte("new b")},
+	})
+	if err == nil {
+		t.Fatal("WriteFilesAtomic() expected an error")
+	}
+
+	got, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "old a" {
+		t.Errorf("a.go = %q, want %q", got, "old a")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected no leftover files or directories, got %d entries",
+			len(entries))
+	}
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceMap resolves JSON pointers into line and column numbers of the YAML
+// (or JSON) source they were parsed from.
+type SourceMap struct {
+	File string
+	root *yaml.Node
+}
+
+// LoadSourceMap parses the file at path into a SourceMap.
+func LoadSourceMap(path string) (*SourceMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return NewSourceMap(path, data)
+}
+
+// NewSourceMap parses data into a SourceMap for the given file name.
+func NewSourceMap(file string, data []byte) (*SourceMap, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("error parsing source: %w", err)
+	}
+
+	return &SourceMap{File: file, root: &root}, nil
+}
+
+// Locate returns the line and column of the node at the given JSON pointer. If
+// the pointer cannot be fully resolved, the position of the deepest node that
+// could be resolved is returned instead.
+func (sm *SourceMap) Locate(pointer string) (int, int) {
+	node := sm.root
+	if node == nil {
+		return 0, 0
+	}
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, segment := range SplitJSONPointer(pointer) {
+		next := childNode(node, segment)
+		if next == nil {
+			break
+		}
+
+		node = next
+	}
+
+	return node.Line, node.Column
+}
+
+// childNode returns the child of node named by a JSON pointer segment. For
+// mapping entries the key node is returned, so positions point at the name of
+// the offending property rather than its value.
+func childNode(node *yaml.Node, segment string) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return keyedValue(node.Content[i], node.Content[i+1])
+			}
+		}
+	case yaml.SequenceNode:
+		var index int
+		if _, err := fmt.Sscanf(segment, "%d", &index); err == nil &&
+			index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+
+	return nil
+}
+
+// keyedValue returns the value node, positioned at its key.
+func keyedValue(key, value *yaml.Node) *yaml.Node {
+	positioned := *value
+	positioned.Line = key.Line
+	positioned.Column = key.Column
+
+	return &positioned
+}
+
+// JSONPointer builds a JSON pointer fragment such as #/components/schemas/Foo
+// from the given reference tokens.
+func JSONPointer(tokens ...string) string {
+	var sb strings.Builder
+
+	sb.WriteString("#")
+
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(escapePointerToken(token))
+	}
+
+	return sb.String()
+}
+
+// ChildJSONPointer appends the given reference tokens to a JSON pointer.
+func ChildJSONPointer(pointer string, tokens ...string) string {
+	return strings.TrimSuffix(pointer, "/") +
+		strings.TrimPrefix(JSONPointer(tokens...), "#")
+}
+
+// SplitJSONPointer splits a JSON pointer into its unescaped reference tokens.
+func SplitJSONPointer(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "#")
+	pointer = strings.TrimPrefix(pointer, "/")
+
+	if pointer == "" {
+		return nil
+	}
+
+	tokens := strings.Split(pointer, "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointerToken(token)
+	}
+
+	return tokens
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func escapePointerToken(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+func unescapePointerToken(token string) string {
+	return pointerUnescaper.Replace(token)
+}
@@ -0,0 +1,95 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/internal"
+)
+
+const testSource = `openapi: 3.0.3
+components:
+  schemas:
+    Foo:
+      type: object
+      properties:
+        bar:
+          type: unknown
+        a/b:
+          type: string
+`
+
+// TestSourceMap_Locate tests the Locate method of SourceMap.
+func TestSourceMap_Locate(t *testing.T) {
+	t.Parallel()
+
+	sm, err := internal.NewSourceMap("spec.yaml", []byte(testSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		pointer    string
+		wantLine   int
+		wantColumn int
+	}{
+		{
+			name:       "schema",
+			pointer:    "#/components/schemas/Foo",
+			wantLine:   4,
+			wantColumn: 5,
+		},
+		{
+			name:       "property",
+			pointer:    "#/components/schemas/Foo/properties/bar",
+			wantLine:   7,
+			wantColumn: 9,
+		},
+		{
+			name:       "escaped property",
+			pointer:    internal.JSONPointer("components", "schemas", "Foo", "properties", "a/b"),
+			wantLine:   9,
+			wantColumn: 9,
+		},
+		{
+			name:       "missing node falls back to nearest parent",
+			pointer:    "#/components/schemas/Foo/properties/missing",
+			wantLine:   6,
+			wantColumn: 7,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			line, column := sm.Locate(test.pointer)
+			if line != test.wantLine || column != test.wantColumn {
+				t.Errorf("Locate() = %d:%d, want %d:%d",
+					line, column, test.wantLine, test.wantColumn)
+			}
+		})
+	}
+}
+
+// TestDiagnostic_String tests the compiler-style format of a Diagnostic.
+func TestDiagnostic_String(t *testing.T) {
+	t.Parallel()
+
+	d := internal.Diagnostic{
+		Severity: internal.SeverityError,
+		Message:  "unsupported type unknown",
+		Pointer:  "#/components/schemas/Foo/properties/bar",
+		File:     "spec.yaml",
+		Line:     7,
+		Column:   9,
+	}
+
+	want := "spec.yaml:7:9: error: unsupported type unknown " +
+		"(#/components/schemas/Foo/properties/bar)"
+
+	if got := d.String(); got != want {
+		t.Errorf("Diagnostic.String() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,14 @@
 package internal
 
-import "github.com/iancoleman/strcase"
+import (
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/iancoleman/strcase"
+)
 
 // OnlyOneTrue returns true if only one of the three arguments is true.
 func OnlyOneTrue(a, b, c bool) bool {
@@ -31,3 +39,90 @@ func ToSnakeCase(s string) string {
 func ToCamelCase(s string) string {
 	return strcase.ToCamel(s)
 }
+
+// ToIdentifier converts the given string to an exported Go identifier in camel
+// case. Characters that can't be part of an identifier are dropped, and
+// identifiers that would start with a digit are prefixed with N.
+func ToIdentifier(s string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+
+		return -1
+	}, strcase.ToCamel(s))
+
+	first, _ := utf8.DecodeRuneInString(name)
+
+	switch {
+	case name == "":
+		return "X"
+	case !unicode.IsLetter(first):
+		return "N" + name
+	}
+
+	return name
+}
+
+// ToPackageName converts the given string to an idiomatic Go package name:
+// lowercase letters and digits only. Names that would be empty, start with a
+// digit, or be a keyword or main, get a pkg prefix or suffix.
+func ToPackageName(s string) string {
+	var sb strings.Builder
+
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	name := sb.String()
+	first, _ := utf8.DecodeRuneInString(name)
+
+	switch {
+	case name == "":
+		return "pkg"
+	case !unicode.IsLetter(first):
+		return "pkg" + name
+	case token.IsKeyword(name) || name == "main":
+		return name + "pkg"
+	}
+
+	return name
+}
+
+// IsPackageName returns true if s is a valid name for a Go package that can be
+// imported.
+func IsPackageName(s string) bool {
+	return token.IsIdentifier(s) && s != "main" && s != "_"
+}
+
+// QuoteGoString returns s as a Go string literal. Multi-line text is emitted as
+// a raw string when it can be represented exactly, so long descriptions stay
+// readable in generated code; everything else is quoted and escaped.
+func QuoteGoString(s string) string {
+	if strings.Contains(s, "\n") && canBackquote(s) {
+		return "`" + s + "`"
+	}
+
+	return strconv.Quote(s)
+}
+
+// canBackquote returns true if s can be written as a raw string literal
+// without changing its value. Raw strings cannot contain backquotes, and
+// carriage returns are discarded from them.
+func canBackquote(s string) bool {
+	for _, r := range s {
+		switch {
+		case r == '`', r == '\r', r == unicode.ReplacementChar,
+			r == '\uFEFF':
+			return false
+		case r == '\n', r == '\t':
+			continue
+		case unicode.IsControl(r):
+			return false
+		}
+	}
+
+	return true
+}
@@ -100,3 +100,108 @@ func TestOnlyOneTrue(t *testing.T) {
 		})
 	}
 }
+
+// TestQuoteGoString tests the QuoteGoString function.
+func TestQuoteGoString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{
+			name: "plain",
+			arg:  "hello",
+			want: `"hello"`,
+		},
+		{
+			name: "quotes and backslashes",
+			arg:  `say "hi" \o/`,
+			want: `"say \"hi\" \\o/"`,
+		},
+		{
+			name: "multi-line",
+			arg:  "line one\nline \"two\"",
+			want: "`line one\nline \"two\"`",
+		},
+		{
+			name: "multi-line with backquote",
+			arg:  "line one\n`two`",
+			want: `"line one\n` + "`two`" + `"`,
+		},
+		{
+			name: "multi-line with carriage return",
+			arg:  "line one\r\nline two",
+			want: `"line one\r\nline two"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := internal.QuoteGoString(test.arg); got != test.want {
+				t.Errorf("QuoteGoString() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestToIdentifier tests the ToIdentifier function.
+func TestToIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{name: "camel case", arg: "origin_config", want: "OriginConfig"},
+		{name: "punctuation", arg: "Origin$Ref[0]", want: "OriginRef0"},
+		{name: "leading digit", arg: "2FAConfig", want: "N2FAConfig"},
+		{name: "keyword", arg: "type", want: "Type"},
+		{name: "nothing left", arg: "!!", want: "X"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := internal.ToIdentifier(test.arg); got != test.want {
+				t.Errorf("ToIdentifier() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestToPackageName tests the ToPackageName function.
+func TestToPackageName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{name: "title", arg: "Edgio REST API v2.0", want: "edgiorestapiv20"},
+		{name: "snake case", arg: "cdn_config", want: "cdnconfig"},
+		{name: "leading digit", arg: "3D Secure", want: "pkg3dsecure"},
+		{name: "keyword", arg: "Type", want: "typepkg"},
+		{name: "main", arg: "main", want: "mainpkg"},
+		{name: "empty", arg: "", want: "pkg"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := internal.ToPackageName(test.arg); got != test.want {
+				t.Errorf("ToPackageName() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -1,30 +1,330 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/stevenpaz/tf-schema-gen/internal"
 	"github.com/stevenpaz/tf-schema-gen/openapi"
 )
 
+// Diagnostics output formats.
+const (
+	diagnosticsFormatText = "text"
+	diagnosticsFormatJSON = "json"
+)
+
+// Bundle formats, for writing every generated file to a single output.
+const (
+	bundleTxtar = "txtar"
+	bundleTar   = "tar"
+	bundleGo    = "go"
+)
+
+// stdio is the path that stands for stdin as the document and for stdout as
+// the output.
+const stdio = "-"
+
+// stdinName is the file name diagnostics in documents read from stdin are
+// reported in.
+const stdinName = "<stdin>"
+
 func main() {
 	RunOpenAPIGen()
 }
 
 func RunOpenAPIGen() {
+	diagnosticsFormat := flag.String(
+		"diagnostics-format",
+		diagnosticsFormatText,
+		"format of reported problems: text or json")
+	verify := flag.Bool(
+		"verify",
+		false,
+		"compile the generated code and run the SDK's InternalValidate on it")
+	sdkPath := flag.String(
+		"sdk-path",
+		"",
+		"local terraform-plugin-sdk/v2 to verify against")
+	renameStrategy := flag.String(
+		"rename-strategy",
+		string(openapi.RenamePrefix),
+		"how to rename reserved or colliding attributes: prefix or error")
+	docsFolderPath := flag.String(
+		"docs",
+		"",
+		"folder to generate Terraform registry documentation into")
+	accTests := flag.Bool(
+		"acc-tests",
+		false,
+		"generate acceptance test scaffolding that is kept once written")
+	examplesFolderPath := flag.String(
+		"examples",
+		"",
+		"folder to generate example configurations into")
+	reportPath := flag.String(
+		"report",
+		"",
+		"file to write a Markdown report of inferred decisions to")
+	refRoot := flag.String(
+		"ref-root",
+		"",
+		"folder external $refs may load files from (default: the document's folder)")
+	var include, exclude, tags stringsFlag
+
+	flag.Var(&include, "include",
+		"only generate schemas matching a glob, or /regexp/ (repeatable)")
+	flag.Var(&exclude, "exclude",
+		"leave out schemas matching a glob, or /regexp/ (repeatable)")
+	flag.Var(&tags, "tag",
+		"only generate schemas used by operations with the tag (repeatable)")
+
+	var groups groupsFlag
+
+	flag.Var(&groups, "group",
+		"generate the schemas of operations with the tags into a package, "+
+			"as path=tag1,tag2 (repeatable)")
+
+	packageByTag := flag.Bool(
+		"package-by-tag",
+		false,
+		"generate the schemas of each tag into a package in internal/service/<tag>")
+	packageName := flag.String(
+		"package",
+		"",
+		"name of the generated package (default: the document title as a package name)")
+	importPath := flag.String(
+		"import-path",
+		"",
+		"import path of the output folder, needed by -group and -package-by-tag")
+	templatesDir := flag.String(
+		"templates",
+		"",
+		"folder of *.tmpl files overriding the built-in templates with the same name")
+
+	bundle := flag.String(
+		"bundle",
+		"",
+		"write every file to the output as one txtar, tar or go file (default txtar for -)")
+
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(),
+			"usage: tf-schema-gen [generate] [flags] <openapi.yaml|-> <output-folder|->")
+		flag.PrintDefaults()
+	}
+
+	// generate is the only command, so naming it is optional.
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "generate" {
+		args = args[1:]
+	}
+
+	flag.CommandLine.Parse(args)
+
 	// check args
-	if len(os.Args) != 3 {
-		fmt.Println("usage: tf-schema-gen <openapi.yaml> <output-folder>")
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if *diagnosticsFormat != diagnosticsFormatText &&
+		*diagnosticsFormat != diagnosticsFormatJSON {
+		fmt.Fprintln(os.Stderr, "error: unknown diagnostics format",
+			*diagnosticsFormat)
+		os.Exit(1)
+	}
+
+	rename, err := openapi.ParseRenameStrategy(*renameStrategy)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
 		os.Exit(1)
 	}
 
 	// get filePath and outputFolderPath from args
-	filePath := os.Args[1]
-	outputFolderPath := os.Args[2]
+	filePath := flag.Arg(0)
+	outputFolderPath := flag.Arg(1)
+
+	if *bundle == "" && outputFolderPath == stdio {
+		*bundle = bundleTxtar
+	}
+
+	if *bundle != "" && *bundle != bundleTxtar && *bundle != bundleTar &&
+		*bundle != bundleGo {
+		fmt.Fprintln(os.Stderr, "error: unknown bundle format", *bundle)
+		os.Exit(1)
+	}
+
+	opts := openapi.Options{
+		Verify:             *verify,
+		SDKPath:            *sdkPath,
+		RenameStrategy:     rename,
+		AccTests:           *accTests,
+		DocsFolderPath:     *docsFolderPath,
+		ExamplesFolderPath: *examplesFolderPath,
+		ReportPath:         *reportPath,
+		RefRoot:            *refRoot,
+		Include:            include,
+		Exclude:            exclude,
+		Tags:               tags,
+		Groups:             groups,
+		PackageByTag:       *packageByTag,
+		ImportPath:         *importPath,
+		PackageName:        *packageName,
+		TemplatesDir:       *templatesDir,
+	}
+
+	var diags internal.Diagnostics
+
+	if filePath == stdio || *bundle != "" {
+		diags, err = generateBundle(filePath, outputFolderPath, *bundle, opts)
+	} else {
+		diags, err = openapi.CreateTFSchemaFromOpenAPI(
+			filePath, outputFolderPath, opts)
+	}
+
+	printDiagnostics(diags, *diagnosticsFormat)
 
-	err := openapi.CreateTFSchemaFromOpenAPI(filePath, outputFolderPath)
 	if err != nil {
-		fmt.Println("error: ", err)
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	if diags.HasErrors() {
 		os.Exit(1)
 	}
 }
+
+// stringsFlag is a flag that can be repeated, collecting every value.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// groupsFlag is a flag that can be repeated, collecting a package group of the
+// form path=tag1,tag2 from every value.
+type groupsFlag []openapi.PackageGroup
+
+func (f *groupsFlag) String() string {
+	groups := make([]string, 0, len(*f))
+	for _, g := range *f {
+		groups = append(groups, g.Path+"="+strings.Join(g.Tags, ","))
+	}
+
+	return strings.Join(groups, " ")
+}
+
+func (f *groupsFlag) Set(value string) error {
+	path, tags, ok := strings.Cut(value, "=")
+	if !ok || path == "" || tags == "" {
+		return fmt.Errorf("expected path=tag1,tag2, got %q", value)
+	}
+
+	*f = append(*f, openapi.PackageGroup{
+		Path: path,
+		Tags: strings.Split(tags, ","),
+	})
+
+	return nil
+}
+
+// generateBundle generates the files for the document at filePath, or stdin,
+// and writes them to output, or stdout, in the bundle format. Without a
+// bundle format, the files are written into the output folder.
+func generateBundle(
+	filePath string,
+	output string,
+	bundle string,
+	opts openapi.Options,
+) (internal.Diagnostics, error) {
+	r, name := io.Reader(os.Stdin), stdinName
+
+	if filePath != stdio {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		r, name = f, filePath
+	}
+
+	folder := output
+	if bundle != "" {
+		// Files in bundles are named relative to the bundle.
+		folder = ""
+	}
+
+	files, diags, err := openapi.GenerateFiles(r, name, folder, opts)
+	if err != nil || diags.HasErrors() {
+		return diags, err
+	}
+
+	if bundle == "" {
+		return diags, openapi.WriteFiles(files)
+	}
+
+	var buf bytes.Buffer
+
+	switch bundle {
+	case bundleTxtar:
+		err = internal.WriteTxtar(&buf, files)
+	case bundleTar:
+		err = internal.WriteTar(&buf, files)
+	case bundleGo:
+		var code []byte
+
+		code, err = internal.MergeGoFiles(goFiles(files))
+		buf.Write(code)
+	}
+
+	if err != nil {
+		return diags, err
+	}
+
+	if output == stdio {
+		_, err = buf.WriteTo(os.Stdout)
+		return diags, err
+	}
+
+	return diags, internal.WriteFileBytes(output, buf.Bytes())
+}
+
+// goFiles returns the Go files that make up the package, leaving out tests
+// and other files, with a note on stderr for each.
+func goFiles(files []internal.File) []internal.File {
+	code := make([]internal.File, 0, len(files))
+
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, ".go") ||
+			strings.HasSuffix(f.Path, "_test.go") {
+			fmt.Fprintf(os.Stderr,
+				"note: %s is left out of the go bundle\n", f.Path)
+
+			continue
+		}
+
+		code = append(code, f)
+	}
+
+	return code
+}
+
+// printDiagnostics writes diagnostics to stderr in the requested format.
+func printDiagnostics(diags internal.Diagnostics, format string) {
+	if format == diagnosticsFormatJSON {
+		diags.WriteJSON(os.Stderr)
+		return
+	}
+
+	diags.WriteText(os.Stderr)
+}
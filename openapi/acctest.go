@@ -0,0 +1,293 @@
+package openapi
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// accTestProviderFileName is the name of the file shared by acceptance tests.
+const accTestProviderFileName = "provider_test.go"
+
+// accTestStep is a configuration applied by an acceptance test, and the
+// attributes checked after applying it.
+type accTestStep struct {
+	Comment string
+	Config  string
+	Checks  []accTestCheck
+}
+
+// accTestCheck checks the state value of an attribute.
+type accTestCheck struct {
+	Key   string
+	Value string
+}
+
+// accTestFileName returns the name of the acceptance test of a schema.
+func accTestFileName(ts *tf.TerraformSchema) string {
+	return ts.NameSnakeCase + "_resource_test.go"
+}
+
+// RenderAccTest renders the acceptance test scaffolding of a schema. Its steps
+// apply a configuration with only the required attributes, one with every
+// attribute, and one changing every attribute that can be updated in place,
+// then import the resource.
+func RenderAccTest(ts *tf.TerraformSchema) ([]byte, error) {
+	return builtinRenderer.accTest(ts)
+}
+
+// accTest is RenderAccTest with the templates of r.
+func (r *renderer) accTest(ts *tf.TerraformSchema) ([]byte, error) {
+	steps := []accTestStep{
+		accTestConfig(ts, "Create with only the required attributes.",
+			false, false),
+		accTestConfig(ts, "Set every optional attribute.", true, false),
+	}
+
+	// Resources without attributes to change skip the update step.
+	update := accTestConfig(ts,
+		"Change every attribute that can be updated in place.", true, true)
+	if update.Config != steps[1].Config {
+		steps = append(steps, update)
+	}
+
+	var importAttrs []string
+	if ts.Resource != nil {
+		importAttrs = ts.Resource.ImportAttributes()
+	}
+
+	return r.renderGo(accTestTemplateName, newGoFile(ts.Scope, nil), map[string]interface{}{
+		"Schema":           ts,
+		"Address":          ts.ResourceName() + ".test",
+		"Steps":            steps,
+		"ImportAttributes": importAttrs,
+	})
+}
+
+// RenderAccTestProvider renders the scaffolding shared by the acceptance tests
+// of a scope.
+func RenderAccTestProvider(scope *tf.TerraformScope) ([]byte, error) {
+	return builtinRenderer.accTestProvider(scope)
+}
+
+// accTestProvider is RenderAccTestProvider with the templates of r.
+func (r *renderer) accTestProvider(
+	scope *tf.TerraformScope,
+) ([]byte, error) {
+	return r.renderGo(accTestProviderTemplateName, newGoFile(scope, nil), map[string]interface{}{
+		"ProviderName": tf.ProviderName,
+		"HasProvider":  scope.Provider != nil && scope.HasResources(),
+	})
+}
+
+// accTestConfig builds the configuration of an acceptance test step. Optional
+// attributes are only set if full is true, and every value is changed from the
+// one the full configuration sets if update is true.
+func accTestConfig(
+	ts *tf.TerraformSchema,
+	comment string,
+	full bool,
+	update bool,
+) accTestStep {
+	step := accTestStep{Comment: comment}
+
+	f := hclwrite.NewEmptyFile()
+	block := f.Body().AppendNewBlock(
+		"resource", []string{ts.ResourceName(), "test"})
+	writeAccTestBody(block.Body(), ts, "", full, update, &step.Checks)
+
+	step.Config = string(hclwrite.Format(f.Bytes()))
+
+	return step
+}
+
+// writeAccTestBody writes the attributes and nested blocks of a schema and
+// records a check for each of them, prefixing keys with prefix. Nothing is
+// recorded if checks is nil.
+func writeAccTestBody(
+	body *hclwrite.Body,
+	ts *tf.TerraformSchema,
+	prefix string,
+	full bool,
+	update bool,
+	checks *[]accTestCheck,
+) {
+	check := func(key, value string) {
+		if checks != nil {
+			*checks = append(*checks, accTestCheck{prefix + key, value})
+		}
+	}
+
+	names := sortedKeys(ts.Properties)
+
+	for _, blocks := range []bool{false, true} {
+		for _, name := range names {
+			prop := ts.Properties[name]
+			if isNestedBlock(prop) != blocks || isReadOnly(prop) ||
+				(!full && !prop.IsRequired()) {
+				continue
+			}
+
+			if blocks {
+				body.AppendNewline()
+				nested := body.AppendNewBlock(name, nil)
+				check(name+".#", "1")
+
+				// Set elements are addressed by hash, so only list elements
+				// can be checked by index.
+				var nestedChecks *[]accTestCheck
+				if prop.Type == tf.TypeList {
+					nestedChecks = checks
+				}
+
+				writeAccTestBody(nested.Body(), prop.Elem.Schema,
+					prefix+name+".0.", full, update && !prop.ForceNew,
+					nestedChecks)
+
+				continue
+			}
+
+			value := placeholderValue(prop)
+			if prop.Example != nil {
+				if v, ok := exampleValue(prop, prop.Example); ok {
+					value = v
+				}
+			}
+
+			// Changing ForceNew attributes, like path parameters, replaces
+			// the resource instead of updating it.
+			if update && !prop.ForceNew {
+				value = changedValue(prop, value)
+			}
+
+			body.SetAttributeValue(name, value)
+
+			switch {
+			case prop.IsCollection():
+				check(name+".#", fmt.Sprint(value.LengthInt()))
+			case prop.Type == tf.TypeMap:
+				check(name+".%", fmt.Sprint(value.LengthInt()))
+			default:
+				check(name, stateString(value))
+			}
+		}
+	}
+}
+
+// changedValue returns a value of the property's type that differs from v.
+// Enum values are replaced with the next allowed value.
+func changedValue(prop tf.TerraformProperty, v cty.Value) cty.Value {
+	t := v.Type()
+
+	switch {
+	case t.IsListType() || t.IsSetType():
+		values := make([]cty.Value, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			_, e := it.Element()
+			values = append(values, changedPrimitive(e, nil))
+		}
+
+		if len(values) == 0 {
+			return v
+		}
+
+		if t.IsSetType() {
+			return cty.SetVal(values)
+		}
+
+		return cty.ListVal(values)
+	case t.IsMapType():
+		values := make(map[string]cty.Value, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, e := it.Element()
+			values[k.AsString()] = changedPrimitive(e, nil)
+		}
+
+		if len(values) == 0 {
+			return v
+		}
+
+		return cty.MapVal(values)
+	default:
+		// Values the validators reject can't be applied, so the value is
+		// left unchanged if no other value passes them.
+		candidates := []cty.Value{changedPrimitive(v, prop.Enum)}
+		if v.Type() == cty.Number && len(prop.Enum) == 0 {
+			candidates = append(candidates, v.Subtract(cty.NumberIntVal(1)))
+		}
+
+		for _, changed := range candidates {
+			if tf.ValidatorsAccept(prop.Validators, ctyJSON(changed)) {
+				return changed
+			}
+		}
+
+		return v
+	}
+}
+
+// ctyJSON returns a primitive value as decoded JSON.
+func ctyJSON(v cty.Value) interface{} {
+	switch v.Type() {
+	case cty.Bool:
+		return v.True()
+	case cty.Number:
+		f, _ := v.AsBigFloat().Float64()
+		return f
+	default:
+		return v.AsString()
+	}
+}
+
+// changedPrimitive returns a primitive value that differs from v, or the next
+// of the enum values if there are any.
+func changedPrimitive(v cty.Value, enum []interface{}) cty.Value {
+	if len(enum) > 0 {
+		for i, e := range enum {
+			if stateString(v) == fmt.Sprint(e) {
+				next, ok := examplePrimitive(
+					typeOfCty(v.Type()), enum[(i+1)%len(enum)])
+				if ok {
+					return next
+				}
+			}
+		}
+
+		return v
+	}
+
+	switch v.Type() {
+	case cty.Bool:
+		return v.Not()
+	case cty.Number:
+		return v.Add(cty.NumberIntVal(1))
+	default:
+		return cty.StringVal(v.AsString() + "-updated")
+	}
+}
+
+// typeOfCty returns the Terraform type of a primitive cty type.
+func typeOfCty(t cty.Type) string {
+	switch t {
+	case cty.Bool:
+		return tf.TypeBool
+	case cty.Number:
+		return tf.TypeFloat
+	default:
+		return tf.TypeString
+	}
+}
+
+// stateString returns a primitive value as Terraform stores it in state.
+func stateString(v cty.Value) string {
+	switch v.Type() {
+	case cty.Bool:
+		return fmt.Sprint(v.True())
+	case cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	default:
+		return v.AsString()
+	}
+}
@@ -0,0 +1,226 @@
+package openapi_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+func TestRenderAccTest(t *testing.T) {
+	s := &openapi3.Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: openapi3.Schemas{
+			"name": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:    "string",
+				Example: "web",
+			}},
+			"mode": openapi3.NewStringSchema().
+				WithEnum("strict", "lax").NewRef(),
+			"enabled": openapi3.NewBoolSchema().NewRef(),
+			"id": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:     "string",
+				ReadOnly: true,
+			}},
+		},
+	}
+
+	ts, diags := openapi.ConvertToTFSchema(
+		"Origin", tf.NewTerrformScope("Test"), s)
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	code, err := openapi.RenderAccTest(ts)
+	if err != nil {
+		t.Fatalf("RenderAccTest() error = %v\n%s", err, code)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	want := []string{
+		`resource "edgio_origin" "test" {
+  name = "web"
+}
+`,
+		`resource "edgio_origin" "test" {
+  enabled = false
+  mode    = "strict"
+  name    = "web"
+}
+`,
+		`resource "edgio_origin" "test" {
+  enabled = true
+  mode    = "lax"
+  name    = "web-updated"
+}
+`,
+	}
+
+	if got := stepConfigs(t, file); !reflect.DeepEqual(got, want) {
+		t.Errorf("step configs = %q, want %q", got, want)
+	}
+}
+
+// TestRenderAccTest_Validators tests that placeholder and changed values stay
+// within the validators of attributes.
+func TestRenderAccTest_Validators(t *testing.T) {
+	one, zero, ten := 1.0, 0.0, 10.0
+
+	s := &openapi3.Schema{
+		Type:     "object",
+		Required: []string{"port", "weight", "slug", "ratio"},
+		Properties: openapi3.Schemas{
+			"port": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type: "integer",
+				Min:  &one,
+				Max:  &ten,
+			}},
+			"weight": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:         "number",
+				Min:          &zero,
+				ExclusiveMin: true,
+			}},
+			"ratio": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:         "number",
+				Min:          &zero,
+				Max:          &one,
+				ExclusiveMin: true,
+				ExclusiveMax: true,
+			}},
+			"slug": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:    "string",
+				Pattern: "^[a-z]+$",
+			}},
+		},
+	}
+
+	ts, diags := openapi.ConvertToTFSchema(
+		"Origin", tf.NewTerrformScope("Test"), s)
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	code, err := openapi.RenderAccTest(ts)
+	if err != nil {
+		t.Fatalf("RenderAccTest() error = %v\n%s", err, code)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	want := []string{
+		`resource "edgio_origin" "test" {
+  port   = 1
+  ratio  = 0.5
+  slug   = "example"
+  weight = 1
+}
+`,
+		`resource "edgio_origin" "test" {
+  port   = 2
+  ratio  = 0.5
+  slug   = "example"
+  weight = 2
+}
+`,
+	}
+
+	// Every attribute is required, so the first two steps are the same.
+	want = []string{want[0], want[0], want[1]}
+
+	if got := stepConfigs(t, file); !reflect.DeepEqual(got, want) {
+		t.Errorf("step configs = %q, want %q", got, want)
+	}
+}
+
+// stepConfigs returns the value of every Config field in file.
+func stepConfigs(t *testing.T, file *ast.File) []string {
+	t.Helper()
+
+	var configs []string
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Config" {
+			lit, ok := kv.Value.(*ast.BasicLit)
+			if !ok {
+				t.Fatalf("Config is not a literal: %T", kv.Value)
+			}
+
+			v, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatalf("invalid string literal %s: %v", lit.Value, err)
+			}
+
+			configs = append(configs, v)
+		}
+
+		return true
+	})
+
+	return configs
+}
+
+// TestRenderAccTest_ForceNew tests that the update step leaves attributes
+// that can't be updated in place unchanged.
+func TestRenderAccTest_ForceNew(t *testing.T) {
+	s := &openapi3.Schema{
+		Type:     "object",
+		Required: []string{"name", "pattern"},
+		Properties: openapi3.Schemas{
+			"name":    openapi3.NewStringSchema().NewRef(),
+			"pattern": openapi3.NewStringSchema().NewRef(),
+		},
+	}
+
+	ts, diags := openapi.ConvertToTFSchema(
+		"Rule", tf.NewTerrformScope("Test"), s)
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	name := ts.Properties["name"]
+	name.ForceNew = true
+	ts.Properties["name"] = name
+
+	code, err := openapi.RenderAccTest(ts)
+	if err != nil {
+		t.Fatalf("RenderAccTest() error = %v\n%s", err, code)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+
+	configs := stepConfigs(t, file)
+	if len(configs) != 3 {
+		t.Fatalf("step configs = %q, want 3", configs)
+	}
+
+	want := `resource "edgio_rule" "test" {
+  name    = "example"
+  pattern = "example-updated"
+}
+`
+	if configs[2] != want {
+		t.Errorf("update step config = %q, want %q", configs[2], want)
+	}
+}
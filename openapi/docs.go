@@ -0,0 +1,306 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// docsPage is the data a resource page is rendered from.
+type docsPage struct {
+	ProviderName string
+	ResourceName string
+	Description  string
+	Example      string
+	Schema       docsSection
+	// Nested holds the sections of nested blocks, breadth first.
+	Nested []docsSection
+	// Import is the command that imports the resource, if it can be.
+	Import string
+}
+
+// docsSection documents the attributes of a schema, grouped as the registry
+// expects.
+type docsSection struct {
+	// Path is the dotted path of a nested block.
+	Path string
+	// Anchor is the HTML anchor parents link a nested block to.
+	Anchor   string
+	Required []docsAttribute
+	Optional []docsAttribute
+	ReadOnly []docsAttribute
+}
+
+// docsAttribute documents a single attribute.
+type docsAttribute struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// docsFileName returns the path of a schema's page relative to the docs
+// folder.
+func docsFileName(ts *tf.TerraformSchema) string {
+	return filepath.Join("resources", ts.NameSnakeCase+".md")
+}
+
+// RenderDocs renders the registry documentation page of a schema, showing
+// example as its example usage.
+func RenderDocs(ts *tf.TerraformSchema, example []byte) ([]byte, error) {
+	return builtinRenderer.docs(ts, example)
+}
+
+// docs is RenderDocs with the templates of r.
+func (r *renderer) docs(ts *tf.TerraformSchema, example []byte) ([]byte, error) {
+	page := docsPage{
+		ProviderName: tf.ProviderName,
+		ResourceName: ts.ResourceName(),
+		Description:  ts.Description,
+		Example:      string(example),
+	}
+
+	if ts.Resource != nil {
+		id := strings.NewReplacer("{", "<", "}", ">").
+			Replace(ts.Resource.ImportIDFormat())
+		page.Import = fmt.Sprintf("terraform import %s.example %s",
+			ts.ResourceName(), id)
+	}
+
+	documented := ts
+	if ts.Resource != nil && ts.Resource.IsAsync() {
+		documented = withTimeouts(ts)
+	}
+
+	schema, queue := documentSchema(documented, "", false)
+	page.Schema = schema
+
+	for i, attr := range page.Schema.Optional {
+		if documented != ts && attr.Name == timeoutsBlock {
+			// The SDK declares timeouts as a single block.
+			page.Schema.Optional[i].Type = "Block, Optional"
+		}
+	}
+
+	// SDKv2 resources always have an id attribute, which schemas may declare
+	// themselves.
+	if _, ok := ts.Properties[tf.IDAttributeName]; !ok {
+		page.Schema.ReadOnly = append([]docsAttribute{{
+			Name:        tf.IDAttributeName,
+			Type:        "String",
+			Description: "The ID of this resource.",
+		}}, page.Schema.ReadOnly...)
+	}
+
+	sort.Slice(page.Schema.ReadOnly, func(i, j int) bool {
+		return page.Schema.ReadOnly[i].Name < page.Schema.ReadOnly[j].Name
+	})
+
+	// Like tfplugindocs, document nested blocks breadth first.
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+
+		section, nested := documentSchema(
+			block.schema, block.path, block.readOnly)
+		section.Path = block.path
+		section.Anchor = block.anchor
+
+		page.Nested = append(page.Nested, section)
+		queue = append(queue, nested...)
+	}
+
+	var buf bytes.Buffer
+	err := r.templates.ExecuteTemplate(&buf, docsTemplateName, page)
+	if err != nil {
+		return nil, fmt.Errorf("error executing template: %w", err)
+	}
+
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n'), nil
+}
+
+// timeoutsBlock is the block the timeouts of resources with long-running
+// operations are configured in.
+const timeoutsBlock = "timeouts"
+
+// withTimeouts returns a copy of the schema of a resource with its timeouts
+// block, for documentation.
+func withTimeouts(ts *tf.TerraformSchema) *tf.TerraformSchema {
+	timeouts := tf.NewTerrformSchema("Timeouts", ts.Scope)
+	timeouts.Inline = true
+
+	ops := map[string]*tf.Operation{
+		"create": ts.Resource.Create,
+		"update": ts.Resource.Update,
+		"delete": ts.Resource.Delete,
+	}
+	for name, op := range ops {
+		if op != nil {
+			prop := tf.TerraformProperty{Type: tf.TypeString}
+			prop.SetOptional(true)
+			timeouts.Properties[name] = prop
+		}
+	}
+
+	block := tf.TerraformProperty{Type: tf.TypeList}
+	block.SetOptional(true)
+	block.SetElemSchema(timeouts)
+
+	copied := *ts
+	copied.Properties = make(map[string]tf.TerraformProperty,
+		len(ts.Properties)+1)
+
+	for name, prop := range ts.Properties {
+		copied.Properties[name] = prop
+	}
+
+	copied.Properties[timeoutsBlock] = block
+
+	return &copied
+}
+
+// docsNestedBlock is a nested block waiting to be documented.
+type docsNestedBlock struct {
+	schema   *tf.TerraformSchema
+	path     string
+	anchor   string
+	readOnly bool
+}
+
+// documentSchema documents the properties of a schema at path, and returns
+// the nested blocks they link to in the order they are listed. Every attribute
+// of a read-only block is itself read-only.
+func documentSchema(
+	ts *tf.TerraformSchema,
+	path string,
+	readOnly bool,
+) (docsSection, []docsNestedBlock) {
+	var (
+		s                            docsSection
+		required, optional, computed []docsNestedBlock
+	)
+
+	for _, name := range sortedKeys(ts.Properties) {
+		prop := ts.Properties[name]
+		attr := docsAttribute{
+			Name:        name,
+			Type:        docsType(prop),
+			Description: docsDescription(prop),
+		}
+
+		propReadOnly := readOnly || isReadOnly(prop)
+
+		var nested []docsNestedBlock
+
+		if isNestedBlock(prop) {
+			block := docsNestedBlock{
+				schema:   prop.Elem.Schema,
+				path:     name,
+				readOnly: propReadOnly,
+			}
+
+			if path != "" {
+				block.path = path + "." + name
+			}
+
+			prefix := "nestedblock--"
+			if propReadOnly {
+				prefix = "nestedatt--"
+				attr.Type = docsCollectionName(prop.Type) + " of Object"
+			}
+
+			block.anchor = prefix + strings.ReplaceAll(block.path, ".", "--")
+
+			link := fmt.Sprintf(
+				"(see [below for nested schema](#%s))", block.anchor)
+			if attr.Description == "" {
+				attr.Description = link
+			} else {
+				attr.Description += " " + link
+			}
+
+			nested = append(nested, block)
+		}
+
+		switch {
+		case propReadOnly:
+			s.ReadOnly = append(s.ReadOnly, attr)
+			computed = append(computed, nested...)
+		case prop.IsRequired():
+			s.Required = append(s.Required, attr)
+			required = append(required, nested...)
+		default:
+			s.Optional = append(s.Optional, attr)
+			optional = append(optional, nested...)
+		}
+	}
+
+	return s, append(append(required, optional...), computed...)
+}
+
+// docsType returns the type of a property as tfplugindocs names it.
+func docsType(prop tf.TerraformProperty) string {
+	switch {
+	case isNestedBlock(prop):
+		t := "Block " + docsCollectionName(prop.Type)
+		if prop.MinItems != nil {
+			t += fmt.Sprintf(", Min: %d", *prop.MinItems)
+		}
+
+		if prop.MaxItems != nil {
+			t += fmt.Sprintf(", Max: %d", *prop.MaxItems)
+		}
+
+		return t
+	case prop.IsCollection(), prop.Type == tf.TypeMap:
+		return docsCollectionName(prop.Type) + " of " +
+			docsPrimitiveName(elemType(prop))
+	default:
+		return docsPrimitiveName(prop.Type)
+	}
+}
+
+// docsCollectionName returns the name of a collection type.
+func docsCollectionName(t string) string {
+	switch t {
+	case tf.TypeSet:
+		return "Set"
+	case tf.TypeMap:
+		return "Map"
+	default:
+		return "List"
+	}
+}
+
+// docsPrimitiveName returns the name of a primitive type.
+func docsPrimitiveName(t string) string {
+	switch t {
+	case tf.TypeBool:
+		return "Boolean"
+	case tf.TypeInt, tf.TypeFloat:
+		return "Number"
+	default:
+		return "String"
+	}
+}
+
+// docsDescription returns the description of a property followed by notes on
+// the values it accepts and its default.
+func docsDescription(prop tf.TerraformProperty) string {
+	var parts []string
+
+	if prop.Description != nil {
+		parts = append(parts, strings.TrimSpace(*prop.Description))
+	}
+
+	parts = append(parts, tf.DescribeValidators(prop.Validators)...)
+
+	if prop.Default != nil {
+		parts = append(parts, fmt.Sprintf("Defaults to `%v`.", prop.Default))
+	}
+
+	return strings.Join(parts, " ")
+}
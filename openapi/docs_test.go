@@ -0,0 +1,200 @@
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+const wantOriginDocs = "---\n" +
+	`# generated by https://github.com/hashicorp/terraform-plugin-docs
+page_title: "edgio_origin Resource - terraform-provider-edgio"
+subcategory: ""
+description: |-
+  An origin server.
+---
+
+# edgio_origin (Resource)
+
+An origin server.
+
+## Example Usage
+
+` + "```terraform" + `
+resource "edgio_origin" "example" {
+  name = "example"
+
+  tls {
+    mode = "strict"
+  }
+}
+` + "```" + `
+
+<!-- schema generated by tfplugindocs -->
+## Schema
+
+### Required
+
+- ` + "`name`" + ` (String) The origin name.
+- ` + "`tls`" + ` (Block List, Max: 1) (see [below for nested schema](#nestedblock--tls))
+
+### Optional
+
+- ` + "`port`" + ` (Number) Must be at least 1. Must be at most 65535. Defaults to ` + "`443`" + `.
+- ` + "`tags`" + ` (Set of String)
+
+### Read-Only
+
+- ` + "`id`" + ` (String) The ID of this resource.
+- ` + "`status`" + ` (List of Object) (see [below for nested schema](#nestedatt--status))
+
+<a id="nestedblock--tls"></a>
+### Nested Schema for ` + "`tls`" + `
+
+Required:
+
+- ` + "`mode`" + ` (String) Must be one of ` + "`strict`, `lax`" + `.
+
+<a id="nestedatt--status"></a>
+### Nested Schema for ` + "`status`" + `
+
+Read-Only:
+
+- ` + "`state`" + ` (String)
+`
+
+func TestRenderDocs(t *testing.T) {
+	min, max := 1.0, 65535.0
+	maxItems := uint64(1)
+
+	s := &openapi3.Schema{
+		Type:        "object",
+		Description: "An origin server.",
+		Required:    []string{"name", "tls"},
+		Properties: openapi3.Schemas{
+			"name": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:        "string",
+				Description: "The origin name.",
+			}},
+			"port": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:    "integer",
+				Min:     &min,
+				Max:     &max,
+				Default: 443.0,
+			}},
+			"tags": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:        "array",
+				UniqueItems: true,
+				Items:       openapi3.NewStringSchema().NewRef(),
+			}},
+			"tls": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:     "object",
+				MaxItems: &maxItems,
+				Required: []string{"mode"},
+				Properties: openapi3.Schemas{
+					"mode": openapi3.NewStringSchema().
+						WithEnum("strict", "lax").NewRef(),
+				},
+			}},
+			"status": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:     "object",
+				ReadOnly: true,
+				Properties: openapi3.Schemas{
+					"state": openapi3.NewStringSchema().NewRef(),
+				},
+			}},
+		},
+	}
+
+	ts, diags := openapi.ConvertToTFSchema(
+		"Origin", tf.NewTerrformScope("Test"), s)
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	example, diags := openapi.RenderExample(ts)
+	if len(diags) > 0 {
+		t.Fatalf("RenderExample() diags = %v", diags)
+	}
+
+	got, err := openapi.RenderDocs(ts, example)
+	if err != nil {
+		t.Fatalf("RenderDocs() error = %v", err)
+	}
+
+	if string(got) != wantOriginDocs {
+		t.Errorf("RenderDocs() =\n%s\nwant\n%s", got, wantOriginDocs)
+	}
+}
+
+// TestRenderDocs_MultiLineDescription tests that the continuation lines of a
+// description stay in the list item of its attribute.
+func TestRenderDocs_MultiLineDescription(t *testing.T) {
+	s := &openapi3.Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: openapi3.Schemas{
+			"name": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:        "string",
+				Description: "The origin name.\n\nShown in logs.\r\nNot unique.",
+			}},
+		},
+	}
+
+	ts, diags := openapi.ConvertToTFSchema(
+		"Origin", tf.NewTerrformScope("Test"), s)
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	got, err := openapi.RenderDocs(ts, nil)
+	if err != nil {
+		t.Fatalf("RenderDocs() error = %v", err)
+	}
+
+	want := "- `name` (String) The origin name.\n\n" +
+		"  Shown in logs.\n" +
+		"  Not unique.\n"
+	if !strings.Contains(string(got), want) {
+		t.Errorf("RenderDocs() =\n%s\nwant it to contain\n%s", got, want)
+	}
+}
+
+// TestRenderDocs_DeclaredID tests that an id attribute the schema declares is
+// documented once.
+func TestRenderDocs_DeclaredID(t *testing.T) {
+	s := &openapi3.Schema{
+		Type: "object",
+		Properties: openapi3.Schemas{
+			"id": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:        "string",
+				Description: "The origin ID.",
+				ReadOnly:    true,
+			}},
+			"name": openapi3.NewStringSchema().NewRef(),
+		},
+	}
+
+	ts, diags := openapi.ConvertToTFSchema(
+		"Origin", tf.NewTerrformScope("Test"), s)
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	got, err := openapi.RenderDocs(ts, nil)
+	if err != nil {
+		t.Fatalf("RenderDocs() error = %v", err)
+	}
+
+	if n := strings.Count(string(got), "- `id`"); n != 1 {
+		t.Errorf("RenderDocs() documents id %d times, want once:\n%s", n, got)
+	}
+
+	want := "- `id` (String) The origin ID.\n"
+	if !strings.Contains(string(got), want) {
+		t.Errorf("RenderDocs() =\n%s\nwant it to contain\n%s", got, want)
+	}
+}
@@ -0,0 +1,499 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// exampleFileName returns the path of a schema's example relative to the
+// examples folder, where tfplugindocs looks for it.
+func exampleFileName(ts *tf.TerraformSchema) string {
+	return filepath.Join("resources", ts.ResourceName(), "resource.tf")
+}
+
+// schemaExample returns the example of an OpenAPI schema: its example value,
+// or else the first of the examples OpenAPI 3.1 allows.
+func schemaExample(s *openapi3.Schema) interface{} {
+	if s.Example != nil {
+		return s.Example
+	}
+
+	if examples, ok := s.Extensions["examples"].([]interface{}); ok &&
+		len(examples) > 0 {
+		return examples[0]
+	}
+
+	return nil
+}
+
+// RenderExample renders an example resource block for a schema from the
+// examples in the OpenAPI document. Required attributes without an example are
+// set to a placeholder. Example values that don't fit the schema are reported
+// as warnings and left out.
+func RenderExample(ts *tf.TerraformSchema) ([]byte, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	f := hclwrite.NewEmptyFile()
+
+	block := f.Body().AppendNewBlock(
+		"resource", []string{ts.ResourceName(), "example"})
+	diags.Append(writeExampleBody(block.Body(), ts, ts.Example, ts.Pointer))
+
+	src := hclwrite.Format(f.Bytes())
+	diags.Append(ValidateExample(ts, src))
+
+	return src, diags
+}
+
+// writeExampleBody writes the attributes of a schema set by the example
+// payload, or required or with an example of their own, followed by its
+// nested blocks.
+func writeExampleBody(
+	body *hclwrite.Body,
+	ts *tf.TerraformSchema,
+	example interface{},
+	pointer string,
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	payload, ok := example.(map[string]interface{})
+	if !ok && example != nil {
+		diags.AddWarning(pointer, "example is not an object and is ignored")
+	}
+
+	// Payloads use API property names, which may differ from attributes.
+	attrNames := make(map[string]string, len(ts.Properties))
+	for name, prop := range ts.Properties {
+		attrNames[name] = name
+		if prop.OriginalName != "" {
+			attrNames[prop.OriginalName] = name
+		}
+	}
+
+	values := make(map[string]interface{}, len(payload))
+
+	for _, key := range sortedKeys(payload) {
+		name, ok := attrNames[key]
+		if !ok {
+			diags.AddWarning(pointer, fmt.Sprintf(
+				"example property '%s' is not an attribute of '%s' and is "+
+					"left out", key, ts.Name))
+
+			continue
+		}
+
+		values[name] = payload[key]
+	}
+
+	names := sortedKeys(ts.Properties)
+
+	// Attributes are written before nested blocks, as terraform fmt does.
+	for _, blocks := range []bool{false, true} {
+		for _, name := range names {
+			prop := ts.Properties[name]
+			if isNestedBlock(prop) != blocks || isReadOnly(prop) {
+				continue
+			}
+
+			value, ok := values[name]
+			if !ok {
+				value = prop.Example
+			}
+
+			if value == nil && !prop.IsRequired() {
+				continue
+			}
+
+			if blocks {
+				diags.Append(writeExampleBlocks(body, name, prop, value))
+			} else {
+				diags.Append(writeExampleAttribute(body, name, prop, value))
+			}
+		}
+	}
+
+	return diags
+}
+
+// writeExampleAttribute sets an attribute to its example value, or to a
+// placeholder if it has none.
+func writeExampleAttribute(
+	body *hclwrite.Body,
+	name string,
+	prop tf.TerraformProperty,
+	example interface{},
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	value := placeholderValue(prop)
+
+	if example != nil {
+		if v, ok := exampleValue(prop, example); ok {
+			value = v
+		} else {
+			diags.AddWarning(prop.Pointer, fmt.Sprintf(
+				"example of '%s' does not match its type; using a placeholder",
+				name))
+		}
+	}
+
+	body.SetAttributeValue(name, value)
+
+	return diags
+}
+
+// writeExampleBlocks writes one nested block per object in the example, or a
+// single block if there is no example.
+func writeExampleBlocks(
+	body *hclwrite.Body,
+	name string,
+	prop tf.TerraformProperty,
+	example interface{},
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	var items []interface{}
+
+	switch v := example.(type) {
+	case nil:
+		items = []interface{}{prop.Elem.Schema.Example}
+	case []interface{}:
+		items = v
+	default:
+		items = []interface{}{v}
+	}
+
+	for _, item := range items {
+		body.AppendNewline()
+		block := body.AppendNewBlock(name, nil)
+		diags.Append(writeExampleBody(
+			block.Body(), prop.Elem.Schema, item, prop.Pointer))
+	}
+
+	return diags
+}
+
+// isNestedBlock returns true if the property is a list or set of nested
+// blocks.
+func isNestedBlock(prop tf.TerraformProperty) bool {
+	return prop.Elem != nil && prop.Elem.Schema != nil
+}
+
+// isReadOnly returns true if the property can't be set in configuration.
+func isReadOnly(prop tf.TerraformProperty) bool {
+	return prop.IsComputed() && !prop.IsOptional() && !prop.IsRequired()
+}
+
+// exampleValue converts the example of a primitive or collection property to
+// a value of the property's type.
+func exampleValue(
+	prop tf.TerraformProperty,
+	example interface{},
+) (cty.Value, bool) {
+	switch prop.Type {
+	case tf.TypeList, tf.TypeSet:
+		items, ok := example.([]interface{})
+		if !ok {
+			return cty.NilVal, false
+		}
+
+		t := elemType(prop)
+		values := make([]cty.Value, 0, len(items))
+
+		for _, item := range items {
+			v, ok := examplePrimitive(t, item)
+			if !ok {
+				return cty.NilVal, false
+			}
+
+			values = append(values, v)
+		}
+
+		if prop.Type == tf.TypeSet {
+			if len(values) == 0 {
+				return cty.SetValEmpty(primitiveCtyType(t)), true
+			}
+
+			return cty.SetVal(values), true
+		}
+
+		if len(values) == 0 {
+			return cty.ListValEmpty(primitiveCtyType(t)), true
+		}
+
+		return cty.ListVal(values), true
+	case tf.TypeMap:
+		entries, ok := example.(map[string]interface{})
+		if !ok {
+			return cty.NilVal, false
+		}
+
+		t := elemType(prop)
+		values := make(map[string]cty.Value, len(entries))
+
+		for k, entry := range entries {
+			v, ok := examplePrimitive(t, entry)
+			if !ok {
+				return cty.NilVal, false
+			}
+
+			values[k] = v
+		}
+
+		if len(values) == 0 {
+			return cty.MapValEmpty(primitiveCtyType(t)), true
+		}
+
+		return cty.MapVal(values), true
+	default:
+		return examplePrimitive(prop.Type, example)
+	}
+}
+
+// examplePrimitive converts a decoded JSON value to a primitive value.
+func examplePrimitive(t string, example interface{}) (cty.Value, bool) {
+	switch t {
+	case tf.TypeString:
+		if s, ok := example.(string); ok {
+			return cty.StringVal(s), true
+		}
+	case tf.TypeBool:
+		if b, ok := example.(bool); ok {
+			return cty.BoolVal(b), true
+		}
+	case tf.TypeInt:
+		if f, ok := example.(float64); ok && f == math.Trunc(f) {
+			return cty.NumberIntVal(int64(f)), true
+		}
+	case tf.TypeFloat:
+		if f, ok := example.(float64); ok {
+			return cty.NumberFloatVal(f), true
+		}
+	}
+
+	return cty.NilVal, false
+}
+
+// placeholderValue returns a value of the property's type to show in
+// examples.
+func placeholderValue(prop tf.TerraformProperty) cty.Value {
+	switch prop.Type {
+	case tf.TypeList:
+		return cty.ListVal([]cty.Value{placeholderPrimitive(elemType(prop))})
+	case tf.TypeSet:
+		return cty.SetVal([]cty.Value{placeholderPrimitive(elemType(prop))})
+	case tf.TypeMap:
+		return cty.MapVal(map[string]cty.Value{
+			"key": placeholderPrimitive(elemType(prop)),
+		})
+	default:
+		if v, ok := examplePrimitive(prop.Type, placeholderJSON(prop)); ok {
+			return v
+		}
+
+		return placeholderPrimitive(prop.Type)
+	}
+}
+
+// placeholderJSON returns the placeholder value of a primitive property as
+// decoded JSON: the first of its enum values, or else the first of a few
+// candidates its validators accept.
+func placeholderJSON(prop tf.TerraformProperty) interface{} {
+	if len(prop.Enum) > 0 {
+		return prop.Enum[0]
+	}
+
+	var candidates []interface{}
+
+	switch prop.Type {
+	case tf.TypeBool:
+		return false
+	case tf.TypeInt, tf.TypeFloat:
+		for _, n := range placeholderNumbers(prop) {
+			candidates = append(candidates, n)
+		}
+	default:
+		candidates = []interface{}{"example", "2006-01-02T15:04:05Z", "2006-01-02"}
+	}
+
+	for _, c := range candidates {
+		if tf.ValidatorsAccept(prop.Validators, c) {
+			return c
+		}
+	}
+
+	return candidates[0]
+}
+
+// placeholderNumbers returns the numbers to try as the placeholder of a
+// number property: 0 and 1, then the bounds of its ranges and the numbers
+// next to them, then the middle of its ranges.
+func placeholderNumbers(prop tf.TerraformProperty) []float64 {
+	numbers := []float64{0, 1}
+
+	near := func(bound *float64) {
+		if bound != nil {
+			numbers = append(numbers, *bound, *bound+1, *bound-1)
+		}
+	}
+
+	var middles []float64
+
+	for _, v := range prop.Validators {
+		var min, max *float64
+
+		switch r := v.(type) {
+		case tf.IntRange:
+			if r.Min != nil {
+				f := float64(*r.Min)
+				min = &f
+			}
+
+			if r.Max != nil {
+				f := float64(*r.Max)
+				max = &f
+			}
+		case tf.FloatRange:
+			min, max = r.Min, r.Max
+		}
+
+		near(min)
+		near(max)
+
+		if min != nil && max != nil && prop.Type == tf.TypeFloat {
+			middles = append(middles, (*min+*max)/2)
+		}
+	}
+
+	return append(numbers, middles...)
+}
+
+// placeholderPrimitive returns a placeholder value of a primitive type.
+func placeholderPrimitive(t string) cty.Value {
+	switch t {
+	case tf.TypeBool:
+		return cty.False
+	case tf.TypeInt, tf.TypeFloat:
+		return cty.NumberIntVal(0)
+	default:
+		return cty.StringVal("example")
+	}
+}
+
+// primitiveCtyType returns the cty type of a primitive type.
+func primitiveCtyType(t string) cty.Type {
+	switch t {
+	case tf.TypeBool:
+		return cty.Bool
+	case tf.TypeInt, tf.TypeFloat:
+		return cty.Number
+	default:
+		return cty.String
+	}
+}
+
+// elemType returns the primitive element type of a collection property. Maps
+// without a declared element type hold strings.
+func elemType(prop tf.TerraformProperty) string {
+	if prop.Elem == nil || prop.Elem.Type == "" {
+		return tf.TypeString
+	}
+
+	return prop.Elem.Type
+}
+
+// ValidateExample checks that an example only declares the schema's resource
+// and only sets attributes and nested blocks the schema lets users set.
+func ValidateExample(ts *tf.TerraformSchema, src []byte) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	f, hclDiags := hclsyntax.ParseConfig(
+		src, exampleFileName(ts), hcl.InitialPos)
+	if hclDiags.HasErrors() {
+		diags.AddError(ts.Pointer, fmt.Sprintf(
+			"example is not valid HCL: %s", hclDiags.Error()))
+
+		return diags
+	}
+
+	for _, block := range f.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 ||
+			block.Labels[0] != ts.ResourceName() {
+			diags.AddError(ts.Pointer, fmt.Sprintf(
+				"example may only declare %s resources", ts.ResourceName()))
+
+			continue
+		}
+
+		diags.Append(validateExampleBody(ts, block.Body, ""))
+	}
+
+	return diags
+}
+
+// validateExampleBody checks the attributes and blocks of an example body
+// against a schema. Names are reported prefixed with prefix.
+func validateExampleBody(
+	ts *tf.TerraformSchema,
+	body *hclsyntax.Body,
+	prefix string,
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	check := func(name string, block bool) (tf.TerraformProperty, bool) {
+		prop, ok := ts.Properties[name]
+
+		switch {
+		case !ok:
+			diags.AddError(ts.Pointer, fmt.Sprintf(
+				"example sets '%s%s', which is not an attribute of '%s'",
+				prefix, name, ts.Name))
+		case isReadOnly(prop):
+			diags.AddError(prop.Pointer, fmt.Sprintf(
+				"example sets '%s%s', which is read-only", prefix, name))
+		case isNestedBlock(prop) != block:
+			kind := "an attribute"
+			if block {
+				kind = "a block"
+			}
+
+			diags.AddError(prop.Pointer, fmt.Sprintf(
+				"example sets '%s%s' as %s, which it is not",
+				prefix, name, kind))
+		default:
+			return prop, true
+		}
+
+		return prop, false
+	}
+
+	attrNames := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		attrNames = append(attrNames, name)
+	}
+
+	sort.Strings(attrNames)
+
+	for _, name := range attrNames {
+		check(name, false)
+	}
+
+	for _, block := range body.Blocks {
+		if prop, ok := check(block.Type, true); ok {
+			diags.Append(validateExampleBody(
+				prop.Elem.Schema, block.Body, prefix+block.Type+"."))
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,187 @@
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// exampleSchema is an origin schema with examples on the schema and on its
+// properties.
+func exampleSchema() *openapi3.Schema {
+	return &openapi3.Schema{
+		Type:     "object",
+		Required: []string{"name", "port"},
+		Example: map[string]interface{}{
+			"name":      "web",
+			"createdAt": "2023-01-01T00:00:00Z",
+			"hosts": []interface{}{
+				map[string]interface{}{"host": "a.example.com"},
+				map[string]interface{}{"host": "b.example.com"},
+			},
+			"unknown": true,
+		},
+		Properties: openapi3.Schemas{
+			"name": openapi3.NewStringSchema().NewRef(),
+			"port": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:    "integer",
+				Example: "not a number",
+			}},
+			"labels": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type: "object",
+				AdditionalProperties: openapi3.AdditionalProperties{
+					Schema: openapi3.NewStringSchema().NewRef(),
+				},
+				Extensions: map[string]interface{}{
+					"examples": []interface{}{
+						map[string]interface{}{"team": "edge"},
+					},
+				},
+			}},
+			"createdAt": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:     "string",
+				ReadOnly: true,
+			}},
+			"hosts": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type: "array",
+				Items: &openapi3.SchemaRef{Value: &openapi3.Schema{
+					Type: "object",
+					Properties: openapi3.Schemas{
+						"host": openapi3.NewStringSchema().NewRef(),
+					},
+				}},
+			}},
+		},
+	}
+}
+
+func TestRenderExample(t *testing.T) {
+	ts, diags := openapi.ConvertToTFSchema(
+		"Origin", tf.NewTerrformScope("Test"), exampleSchema())
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	got, diags := openapi.RenderExample(ts)
+
+	want := `resource "edgio_origin" "example" {
+  labels = {
+    team = "edge"
+  }
+  name = "web"
+  port = 0
+
+  hosts {
+    host = "a.example.com"
+  }
+
+  hosts {
+    host = "b.example.com"
+  }
+}
+`
+
+	if string(got) != want {
+		t.Errorf("RenderExample() =\n%s\nwant\n%s", got, want)
+	}
+
+	wantDiags := []string{
+		"example property 'unknown' is not an attribute of 'Origin'",
+		"example of 'port' does not match its type",
+	}
+
+	if len(diags) != len(wantDiags) {
+		t.Fatalf("RenderExample() diags = %v, want %d", diags, len(wantDiags))
+	}
+
+	for i, want := range wantDiags {
+		if diags[i].Severity != internal.SeverityWarning ||
+			!strings.Contains(diags[i].Message, want) {
+			t.Errorf("diags[%d] = %v, want warning containing %q",
+				i, diags[i], want)
+		}
+	}
+}
+
+func TestValidateExample(t *testing.T) {
+	ts, diags := openapi.ConvertToTFSchema(
+		"Origin", tf.NewTerrformScope("Test"), exampleSchema())
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			src: `resource "edgio_origin" "x" {
+  name = "web"
+  hosts {
+    host = "a"
+  }
+}`,
+		},
+		{
+			name:    "unknown attribute",
+			src:     `resource "edgio_origin" "x" { nmae = "web" }`,
+			wantErr: "example sets 'nmae', which is not an attribute",
+		},
+		{
+			name:    "read-only attribute",
+			src:     `resource "edgio_origin" "x" { created_at = "now" }`,
+			wantErr: "example sets 'created_at', which is read-only",
+		},
+		{
+			name: "unknown nested attribute",
+			src: `resource "edgio_origin" "x" {
+  hosts {
+    hots = "a"
+  }
+}`,
+			wantErr: "example sets 'hosts.hots'",
+		},
+		{
+			name:    "attribute as block",
+			src:     `resource "edgio_origin" "x" { hosts = [] }`,
+			wantErr: "example sets 'hosts' as an attribute",
+		},
+		{
+			name:    "other resource",
+			src:     `resource "edgio_property" "x" {}`,
+			wantErr: "example may only declare edgio_origin resources",
+		},
+		{
+			name:    "invalid HCL",
+			src:     `resource "edgio_origin" "x" {`,
+			wantErr: "example is not valid HCL",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			diags := openapi.ValidateExample(ts, []byte(tt.src))
+
+			if tt.wantErr == "" {
+				if len(diags) > 0 {
+					t.Errorf("ValidateExample() diags = %v, want none", diags)
+				}
+
+				return
+			}
+
+			if !diags.HasErrors() ||
+				!strings.Contains(diags.Error(), tt.wantErr) {
+				t.Errorf("ValidateExample() diags = %v, want error %q",
+					diags, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaFilter selects the component schemas to generate by name and by the
+// tags of the operations that use them.
+type schemaFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	// reachable holds the schemas reachable from operations with the
+	// selected tags, or is nil if no tags are selected.
+	reachable map[string]bool
+}
+
+// newSchemaFilter compiles the filters in opts for doc.
+func newSchemaFilter(doc *openapi3.T, opts Options) (*schemaFilter, error) {
+	var (
+		f   schemaFilter
+		err error
+	)
+
+	if f.include, err = compilePatterns(opts.Include); err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+
+	if f.exclude, err = compilePatterns(opts.Exclude); err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	if len(opts.Tags) > 0 {
+		f.reachable = reachableSchemas(doc, opts.Tags)
+	}
+
+	return &f, nil
+}
+
+// selects returns true if the named schema is generated.
+func (f *schemaFilter) selects(name string) bool {
+	if f.reachable != nil && !f.reachable[name] {
+		return false
+	}
+
+	if len(f.include) > 0 && !matchesAny(f.include, name) {
+		return false
+	}
+
+	return !f.excludes(name)
+}
+
+// excludes returns true if the named schema matches an exclude pattern.
+func (f *schemaFilter) excludes(name string) bool {
+	return matchesAny(f.exclude, name)
+}
+
+// compilePatterns compiles schema name patterns. Patterns between slashes,
+// like /Error$/, are regular expressions, and all others are globs, where *
+// matches any run of characters and ? any single one.
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, p := range patterns {
+		expr := globToRegexp(p)
+		if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			expr = p[1 : len(p)-1]
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, re)
+	}
+
+	return res, nil
+}
+
+// globToRegexp returns the anchored regular expression a glob stands for.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return sb.String()
+}
+
+func matchesAny(res []*regexp.Regexp, name string) bool {
+	for _, re := range res {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reachableSchemas returns the component schemas that the parameters, request
+// bodies and responses of the operations with any of tags reference, directly
+// or through other schemas.
+func reachableSchemas(doc *openapi3.T, tags []string) map[string]bool {
+	selected := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		selected[tag] = true
+	}
+
+	reachable := make(map[string]bool)
+	visited := make(map[*openapi3.Schema]bool)
+
+	var walk func(ref *openapi3.SchemaRef)
+
+	walk = func(ref *openapi3.SchemaRef) {
+		if ref == nil || ref.Value == nil {
+			return
+		}
+
+		if name := componentName(ref.Ref); name != "" {
+			reachable[name] = true
+		}
+
+		s := ref.Value
+		if visited[s] {
+			return
+		}
+
+		visited[s] = true
+
+		for _, prop := range s.Properties {
+			walk(prop)
+		}
+
+		walk(s.Items)
+		walk(s.Not)
+
+		if s.AdditionalProperties.Schema != nil {
+			walk(s.AdditionalProperties.Schema)
+		}
+
+		for _, refs := range []openapi3.SchemaRefs{s.AllOf, s.AnyOf, s.OneOf} {
+			for _, ref := range refs {
+				walk(ref)
+			}
+		}
+	}
+
+	walkContent := func(content openapi3.Content) {
+		for _, mt := range content {
+			walk(mt.Schema)
+		}
+	}
+
+	for _, item := range doc.Paths {
+		for _, op := range item.Operations() {
+			if !hasAnyTag(op, selected) {
+				continue
+			}
+
+			for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+				for _, p := range params {
+					if p.Value != nil {
+						walk(p.Value.Schema)
+						walkContent(p.Value.Content)
+					}
+				}
+			}
+
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				walkContent(op.RequestBody.Value.Content)
+			}
+
+			for _, resp := range op.Responses {
+				if resp.Value != nil {
+					walkContent(resp.Value.Content)
+				}
+			}
+		}
+	}
+
+	return reachable
+}
+
+// hasAnyTag returns true if op has any of the selected tags.
+func hasAnyTag(op *openapi3.Operation, selected map[string]bool) bool {
+	for _, tag := range op.Tags {
+		if selected[tag] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const filterSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /rules:
+    post:
+      tags: [cdn]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "400":
+          description: bad request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+  /properties:
+    get:
+      tags: [accounts]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/PropertyPage'
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        origin:
+          $ref: '#/components/schemas/Origin'
+    Origin:
+      type: object
+      properties:
+        host:
+          type: string
+    Error:
+      type: object
+      properties:
+        message:
+          type: string
+    Property:
+      type: object
+      properties:
+        name:
+          type: string
+    PropertyPage:
+      type: object
+      properties:
+        items:
+          type: array
+          items:
+            $ref: '#/components/schemas/Property'
+`
+
+func TestOpenAPI3ToTerraform_Filters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(filterSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     openapi.Options
+		want     []string
+		excluded int
+	}{
+		{
+			name: "all",
+			want: []string{"Error", "Origin", "Property", "PropertyPage", "Rule"},
+		},
+		{
+			name: "tag",
+			opts: openapi.Options{Tags: []string{"cdn"}},
+			want: []string{"Error", "Origin", "Rule"},
+		},
+		{
+			name: "tag and exclude",
+			opts: openapi.Options{Tags: []string{"cdn"}, Exclude: []string{"Error"}},
+			want: []string{"Origin", "Rule"},
+		},
+		{
+			name: "include regexp",
+			opts: openapi.Options{Include: []string{"/^(Rule|Prop)/"}},
+			want: []string{"Origin", "Property", "PropertyPage", "Rule"},
+		},
+		{
+			name: "exclude glob",
+			opts: openapi.Options{Exclude: []string{"*Page", "Orig?n"}},
+			want: []string{"Error", "Origin", "Property", "Rule"},
+			// Origin is referenced by Rule, so it is generated anyway.
+			excluded: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			scope, diags := openapi.OpenAPI3ToTerraform(path, tt.opts)
+			if diags.HasErrors() {
+				t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+			}
+
+			var got []string
+			for _, ts := range scope.Schemas {
+				got = append(got, ts.Name)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("schemas = %v, want %v", got, tt.want)
+			}
+
+			excluded := 0
+			for _, d := range diags {
+				if strings.Contains(d.Message, "is excluded") {
+					excluded++
+				}
+			}
+
+			if excluded != tt.excluded {
+				t.Errorf("diags = %v, want %d excluded schemas generated anyway",
+					diags, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestOpenAPI3ToTerraform_InvalidPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(filterSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{
+		Include: []string{"/(/"},
+	})
+	if !diags.HasErrors() {
+		t.Errorf("OpenAPI3ToTerraform() diags = %v, want an error", diags)
+	}
+}
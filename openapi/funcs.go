@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"text/template"
+
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// propertyFuncs are the functions templates inspect properties with, so they
+// can render a property for any output without precomputed strings.
+var propertyFuncs = template.FuncMap{
+	"isNested":     isNestedBlock,
+	"elemType":     primitiveElemType,
+	"goType":       goType,
+	"tfsdkType":    tfsdkType,
+	"validators":   validators,
+	"hasDefault":   hasDefault,
+	"originalName": originalName,
+}
+
+// primitiveElemType returns the primitive element type of a list, set or map
+// property, or an empty string for nested blocks and primitive properties.
+func primitiveElemType(prop tf.TerraformProperty) string {
+	if isNestedBlock(prop) ||
+		(!prop.IsCollection() && prop.Type != tf.TypeMap) {
+		return ""
+	}
+
+	return elemType(prop)
+}
+
+// goType returns the Go type of the value schema.ResourceData holds for a
+// property.
+func goType(prop tf.TerraformProperty) string {
+	switch prop.Type {
+	case tf.TypeString:
+		return "string"
+	case tf.TypeBool:
+		return "bool"
+	case tf.TypeInt:
+		return "int"
+	case tf.TypeFloat:
+		return "float64"
+	case tf.TypeSet:
+		return "*schema.Set"
+	case tf.TypeMap:
+		return "map[string]interface{}"
+	default:
+		return "[]interface{}"
+	}
+}
+
+// tfsdkType returns the terraform-plugin-framework type of the value of a
+// property, as in a model struct with tfsdk tags.
+func tfsdkType(prop tf.TerraformProperty) string {
+	switch prop.Type {
+	case tf.TypeString:
+		return "types.String"
+	case tf.TypeBool:
+		return "types.Bool"
+	case tf.TypeInt:
+		return "types.Int64"
+	case tf.TypeFloat:
+		return "types.Float64"
+	case tf.TypeSet:
+		return "types.Set"
+	case tf.TypeMap:
+		return "types.Map"
+	default:
+		return "types.List"
+	}
+}
+
+// validators returns the rules the values of a property must follow, which
+// templates can inspect or render with SDKv2, Framework and Describe.
+func validators(prop tf.TerraformProperty) []tf.Validator {
+	return prop.Validators
+}
+
+// hasDefault returns true if the property has a default value the schema
+// declares.
+func hasDefault(prop tf.TerraformProperty) bool {
+	return prop.DefaultValue() != ""
+}
+
+// originalName returns the name in the API of the property with the
+// attribute name.
+func originalName(name string, prop tf.TerraformProperty) string {
+	if prop.OriginalName == "" {
+		return name
+	}
+
+	return prop.OriginalName
+}
@@ -3,86 +3,544 @@ package openapi
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
 )
 
-const schemaTemplate = `// Code generated by github.com/stevenpaz/tf-schema-gen; DO NOT EDIT.
-//
-package {{.Scope.NameSnakeCase}}
-
-import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	{{if .HasValidateFuncs -}}"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"{{end}}
-	{{if .HasValidateFuncs -}}"errors"{{end}}
-)
+// Options configures CreateTFSchemaFromOpenAPI.
+type Options struct {
+	// Verify compiles the generated package against the Terraform SDK and
+	// runs InternalValidate on every schema before anything is written.
+	Verify bool
+	// SDKPath is a local copy of terraform-plugin-sdk/v2 to verify against.
+	// If empty, tf.SDKVersion is resolved from the module cache, or else
+	// through the Go module proxy.
+	SDKPath string
+	// RenameStrategy handles attribute names that are reserved by Terraform
+	// or collide once snake cased. Defaults to RenamePrefix.
+	RenameStrategy RenameStrategy
+	// DocsFolderPath is where registry documentation is generated, in the
+	// layout tfplugindocs uses. No documentation is generated if empty.
+	DocsFolderPath string
+	// AccTests generates acceptance test scaffolding for every resource.
+	// Scaffolding is only written where it doesn't exist yet.
+	AccTests bool
+	// ExamplesFolderPath is where example configurations are generated, in
+	// the layout tfplugindocs uses. No examples are generated if empty.
+	ExamplesFolderPath string
+	// ReportPath is where a Markdown report of the inferences made about the
+	// document is written. No report is written if empty.
+	ReportPath string
+	// RefRoot is the folder external references may load files from.
+	// Defaults to the folder of the document.
+	RefRoot string
+	// Include limits the generated schemas to those whose name matches any
+	// of the patterns. Patterns are globs, or regular expressions when
+	// enclosed in slashes, like /Error$/.
+	Include []string
+	// Exclude leaves out the schemas whose name matches any of the patterns.
+	// Schemas that generated schemas reference are generated regardless.
+	Exclude []string
+	// Tags limits the generated schemas to those reachable from operations
+	// with any of the tags.
+	Tags []string
+	// Groups split the generated code into a package per group, holding the
+	// schemas the operations with the tags of the group use. Everything
+	// else, like the provider, stays in the root package of the output
+	// folder.
+	Groups []PackageGroup
+	// PackageByTag splits the generated code into a package per tag that is
+	// not in any of Groups, in internal/service/<tag>.
+	PackageByTag bool
+	// ImportPath is the import path of the output folder, which packages
+	// the code is split into import each other by.
+	ImportPath string
+	// PackageName is the name of the root package of the generated code.
+	// Defaults to the title of the document, as a Go package name.
+	PackageName string
+	// TemplatesDir is a folder of templates that override the built-in
+	// templates with the same name. See LoadTemplates.
+	TemplatesDir string
+}
 
-const {{.NameCamelCase}}ResourceName = "edgio_{{.NameSnakeCase}}"
+// CreateTFSchemaFromOpenAPI generates Terraform schemas for the OpenAPI
+// document at path into outputFolderPath. Problems found in the document are
+// returned as diagnostics; nothing is written if any of them is an error.
+func CreateTFSchemaFromOpenAPI(
+	path string,
+	outputFolderPath string,
+	opts Options,
+) (internal.Diagnostics, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return internal.Diagnostics{{
+			Severity: internal.SeverityError,
+			Message:  err.Error(),
+			File:     path,
+		}}, nil
+	}
+	defer f.Close()
 
-func Get{{.NameCamelCase}}Schema() map[string]*schema.Schema {
-	return map[string]*schema.Schema{
-		{{range $key, $value := .Properties -}}
-		"{{$key}}": {
-			Type: schema.{{$value.Type}},
-			{{if $value.Description}}Description: "{{$value.Description}}",{{end}}
-			{{if $value.Required}}Required: true,{{end}}
-			{{if $value.Computed}}Computed: true,{{end}}
-			{{if $value.Optional}}Optional: true,{{end}}
-			{{if $value.ValidateFunc}}ValidateDiagFunc: {{$value.ValidateFunc}},{{end}}
-		},
-		{{end}}
+	files, diags, err := GenerateFiles(f, path, outputFolderPath, opts)
+	if err != nil || diags.HasErrors() {
+		return diags, err
 	}
+
+	return diags, WriteFiles(files)
 }
-`
 
-func CreateTFSchemaFromOpenAPI(path string, outputFolderPath string) error {
-	// Parse OpenAPI 3.0 Document.
-	scope, err := OpenAPI3ToTerraform(path)
+// GenerateFiles generates the files for the document read from r into
+// outputFolderPath, without writing them. name is the file diagnostics are
+// reported in. Files that are kept once written, like acceptance test
+// scaffolding, are marked as such. If the code of a schema fails to format,
+// it is kept for inspection in a temporary file the error names.
+func GenerateFiles(
+	r io.Reader,
+	name string,
+	outputFolderPath string,
+	opts Options,
+) ([]internal.File, internal.Diagnostics, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return fmt.Errorf("error converting to TF schema: %w", err)
+		return nil, nil, fmt.Errorf("error reading document: %w", err)
 	}
 
-	// Load schema file template.
-	tmpl, err := template.New("schema").Parse(schemaTemplate)
+	rnd, err := newRenderer(opts.TemplatesDir)
 	if err != nil {
-		return fmt.Errorf("error parsing template: %w", err)
+		return nil, nil, err
+	}
+
+	scope, diags := convertDocument(data, name, opts)
+	if diags.HasErrors() {
+		return nil, diags, nil
 	}
 
-	// If output folder doesn't exist, create it.
-	if _, err := os.Stat(outputFolderPath); os.IsNotExist(err) {
-		err = os.Mkdir(outputFolderPath, 0o755)
+	// Problems found after converting the document are located in it too.
+	sm, _ := internal.NewSourceMap(name, data)
+
+	// Go files are rendered relative to the output folder, which is where
+	// they are verified from too.
+	files := make([]internal.File, 0, len(scope.Schemas))
+
+	for _, ts := range scope.Schemas {
+		dir := packageDir(ts.Package)
+
+		code, err := rnd.schema(ts)
 		if err != nil {
-			return fmt.Errorf("error creating output directory: %w", err)
+			if code != nil {
+				return nil, diags, keepUnformatted(ts, code, err)
+			}
+
+			return nil, diags, err
 		}
+
+		files = append(files, internal.File{
+			Path: filepath.Join(dir, schemaFileName(ts)),
+			Data: code,
+		})
 	}
 
-	// Write each schema to its own file.
-	for _, ts := range scope.Schemas {
-		// Execute template with schema data.
-		var buf bytes.Buffer
-		if err := tmpl.Execute(&buf, ts); err != nil {
-			return fmt.Errorf("error executing template: %w", err)
+	if scope.HasResources() {
+		resources, err := rnd.resourceFiles(scope)
+		if err != nil {
+			return nil, diags, err
 		}
 
-		// Format the generated Go code.
-		formattedBytes, err := internal.FormatGoCode(buf.Bytes())
+		files = append(files, resources...)
+	}
+
+	if opts.AccTests {
+		scaffolds, err := rnd.accTests(scope)
 		if err != nil {
-			internal.WriteFileBytes(
-				fmt.Sprintf("%s/%s_schema_err.go", outputFolderPath, ts.NameSnakeCase),
-				buf.Bytes())
-			return err
+			return nil, diags, err
+		}
+
+		files = append(files, scaffolds...)
+	}
+
+	if opts.Verify {
+		verifyDiags, err := VerifyGeneratedCode(scope, files, opts)
+		if err != nil {
+			return nil, diags, err
+		}
+
+		verifyDiags.Locate(sm)
+		diags.Append(verifyDiags)
+
+		if diags.HasErrors() {
+			return nil, diags, nil
+		}
+	}
+
+	for i := range files {
+		files[i].Path = filepath.Join(outputFolderPath, files[i].Path)
+	}
+
+	if opts.DocsFolderPath != "" || opts.ExamplesFolderPath != "" {
+		docs, docsDiags, err := rnd.documentation(scope, opts)
+		if err != nil {
+			return nil, diags, err
+		}
+
+		docsDiags.Locate(sm)
+		diags.Append(docsDiags)
+
+		if diags.HasErrors() {
+			return nil, diags, nil
+		}
+
+		files = append(files, docs...)
+	}
+
+	if opts.ReportPath != "" {
+		files = append(files, internal.File{
+			Path: opts.ReportPath,
+			Data: RenderReport(scope),
+		})
+	}
+
+	return files, diags, nil
+}
+
+// WriteFiles writes files, creating the folders they are written to.
+// Scaffolding is maintained by hand once written, so it never replaces a
+// file.
+func WriteFiles(files []internal.File) error {
+	write := make([]internal.File, 0, len(files))
+
+	for _, f := range files {
+		if f.Scaffold {
+			if _, err := os.Stat(f.Path); !os.IsNotExist(err) {
+				continue
+			}
+		}
+
+		write = append(write, f)
+	}
+
+	return internal.WriteFilesAtomic(write)
+}
+
+// resourceFiles renders the resources of every schema managed through
+// the API, the conversions of every schema, and the code they share, into the
+// folders of their packages.
+func (r *renderer) resourceFiles(
+	scope *tf.TerraformScope,
+) ([]internal.File, error) {
+	var files []internal.File
+
+	packages := append([]*tf.Package{nil}, scope.Packages...)
+
+	for _, pkg := range packages {
+		pkgFiles, err := r.packageResourceFiles(scope, pkg)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, pkgFiles...)
+	}
+
+	return files, nil
+}
+
+// packageResourceFiles renders the resource files of the schemas in a
+// package, where nil is the root package, and the code they share.
+func (r *renderer) packageResourceFiles(
+	scope *tf.TerraformScope,
+	pkg *tf.Package,
+) ([]internal.File, error) {
+	var files []internal.File
+
+	add := func(name string, render func() ([]byte, error)) error {
+		code, err := render()
+		if err != nil {
+			return fmt.Errorf("error rendering %s: %w", name, err)
+		}
+
+		files = append(files, internal.File{
+			Path: filepath.Join(packageDir(pkg), name),
+			Data: code,
+		})
+
+		return nil
+	}
+
+	schemas := scope.SchemasIn(pkg)
+
+	var hasResources, hasAsync bool
+
+	for _, ts := range schemas {
+		if ts.Resource != nil {
+			hasResources = true
+			hasAsync = hasAsync || ts.Resource.IsAsync()
+		}
+	}
+
+	type sharedFile struct {
+		name   string
+		render func() ([]byte, error)
+	}
+
+	// inPackage renders a template shared by the files of the package.
+	inPackage := func(name string) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			return r.renderGo(name, newGoFile(scope, pkg), scope)
+		}
+	}
+
+	var shared []sharedFile
+
+	// The client is only declared once, so that every package uses the one
+	// the provider configures.
+	switch {
+	case pkg == scope.SharedPackage():
+		shared = append(shared,
+			sharedFile{clientFileName, inPackage(clientTemplateName)})
+	case hasResources || (pkg == nil && scope.Provider != nil):
+		shared = append(shared,
+			sharedFile{clientFileName, inPackage(clientAliasTemplateName)})
+	}
+
+	if len(schemas) > 0 {
+		shared = append(shared, sharedFile{
+			resourceHelpersFileName, inPackage(resourceHelpersTemplateName),
+		})
+	}
+
+	if hasResources {
+		shared = append(shared, sharedFile{
+			resourceTestHelpersFileName, inPackage(resourceTestHelpersTemplateName),
+		})
+	}
+
+	if pkg == nil && scope.Provider != nil {
+		shared = append(shared, sharedFile{providerFileName, func() ([]byte, error) {
+			return r.provider(scope)
+		}})
+	}
+
+	if hasAsync {
+		shared = append(shared, sharedFile{asyncFileName, inPackage(asyncTemplateName)})
+	}
+
+	for _, f := range shared {
+		if err := add(f.name, f.render); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ts := range schemas {
+		ts := ts
+
+		if err := add(expandFileName(ts), func() ([]byte, error) {
+			return r.expand(ts)
+		}); err != nil {
+			return nil, err
 		}
 
-		// Write generate code to file.
-		err = internal.WriteFileBytes(
-			fmt.Sprintf("%s/%s_schema.go", outputFolderPath, ts.NameSnakeCase),
-			formattedBytes)
+		if ts.Resource == nil {
+			continue
+		}
+
+		if err := add(resourceFileName(ts), func() ([]byte, error) {
+			return r.resource(ts)
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := add(resourceTestFileName(ts), func() ([]byte, error) {
+			return r.resourceTest(ts)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// accTests renders the acceptance test scaffolding of every resource, and
+// the scaffolding they share.
+func (r *renderer) accTests(
+	scope *tf.TerraformScope,
+) ([]internal.File, error) {
+	files := make([]internal.File, 0, len(scope.Schemas)+1)
+
+	code, err := r.accTestProvider(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	files = append(files, internal.File{
+		Path:     accTestProviderFileName,
+		Data:     code,
+		Scaffold: true,
+	})
+
+	for _, ts := range scope.Schemas {
+		if ts.Resource == nil {
+			continue
+		}
+
+		code, err := r.accTest(ts)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		files = append(files, internal.File{
+			Path:     accTestFileName(ts),
+			Data:     code,
+			Scaffold: true,
+		})
+	}
+
+	return files, nil
+}
+
+// documentation renders the example and the documentation page of every
+// resource into the folders set in opts. Schemas that are only nested blocks
+// are documented on the pages of the resources they are nested in.
+func (r *renderer) documentation(
+	scope *tf.TerraformScope,
+	opts Options,
+) ([]internal.File, internal.Diagnostics, error) {
+	var diags internal.Diagnostics
+
+	files := make([]internal.File, 0, 2*len(scope.Schemas))
+
+	for _, ts := range scope.Schemas {
+		if ts.Resource == nil {
+			continue
 		}
+
+		example, exampleDiags := RenderExample(ts)
+		diags.Append(exampleDiags)
+
+		if opts.ExamplesFolderPath != "" {
+			files = append(files, internal.File{
+				Path: filepath.Join(
+					opts.ExamplesFolderPath, exampleFileName(ts)),
+				Data: example,
+			})
+		}
+
+		if opts.DocsFolderPath != "" {
+			data, err := r.docs(ts, example)
+			if err != nil {
+				return nil, diags, err
+			}
+
+			files = append(files, internal.File{
+				Path: filepath.Join(opts.DocsFolderPath, docsFileName(ts)),
+				Data: data,
+			})
+		}
+	}
+
+	return files, diags, nil
+}
+
+// schemaFileName returns the name of the file a schema is generated into.
+func schemaFileName(ts *tf.TerraformSchema) string {
+	return ts.NameSnakeCase + "_schema.go"
+}
+
+// templateFuncs are the functions available to templates.
+var templateFuncs = template.FuncMap{
+	"snake":         internal.ToSnakeCase,
+	"camel":         internal.ToCamelCase,
+	"quote":         internal.QuoteGoString,
+	"indent":        indent,
+	"hangingIndent": hangingIndent,
+}
+
+// indent indents every non-empty line of s by n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hangingIndent indents every non-empty line of s but the first by n spaces,
+// so a multi-line string continues the Markdown list item it starts.
+func hangingIndent(n int, s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	first, rest, ok := strings.Cut(s, "\n")
+	if !ok {
+		return s
+	}
+
+	return first + "\n" + indent(n, rest)
+}
+
+// keepUnformatted writes the code of a schema that failed to format to a
+// temporary file, outside of the output folder, and returns err with its path.
+func keepUnformatted(ts *tf.TerraformSchema, code []byte, err error) error {
+	f, createErr := os.CreateTemp("", ts.NameSnakeCase+"_schema_*.go")
+	if createErr != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, writeErr := f.Write(code); writeErr != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return fmt.Errorf("%w (unformatted code written to %s)", err, f.Name())
+}
+
+// RenderSchema renders the Go source of a schema. If the rendered code cannot
+// be formatted, the unformatted code is returned along with the error.
+func RenderSchema(ts *tf.TerraformSchema) ([]byte, error) {
+	return builtinRenderer.schema(ts)
+}
+
+// schema is RenderSchema with the templates of r.
+func (r *renderer) schema(ts *tf.TerraformSchema) ([]byte, error) {
+	return r.renderGo(schemaTemplateName, newGoFile(ts.Scope, ts.Package), ts)
+}
+
+// renderGo executes a template for a file and formats the Go code it renders,
+// with the imports of the packages it refers to. If the code cannot be
+// formatted, the unformatted code is returned along with the error.
+func renderGo(
+	tmpl *template.Template,
+	file *goFile,
+	data interface{},
+) ([]byte, error) {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("error cloning template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(file.funcs()).Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("error executing template: %w", err)
+	}
+
+	code, err := file.addImports(buf.Bytes())
+	if err != nil {
+		return buf.Bytes(), err
+	}
+
+	formatted, err := internal.FormatGoCode(code)
+	if err != nil {
+		return buf.Bytes(), err
 	}
 
-	return nil
+	return formatted, nil
 }
@@ -0,0 +1,279 @@
+package openapi_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// adversarialStrings are descriptions and enum values that break naive
+// interpolation into Go source.
+var adversarialStrings = []string{
+	`say "hello"`,
+	`C:\path\to\file`,
+	"line one\nline two",
+	"markdown with `code` and\nmore lines",
+	"windows\r\nline endings",
+	"tab\tseparated",
+	"trailing backslash \\",
+	"unicode ✓ and emoji 🚀",
+	"control \x00 \x1b characters",
+	"invalid utf-8 \xff",
+	"*/ closes a comment /*",
+	"{{.Name}} looks like a template",
+	"",
+}
+
+// TestRenderSchema_Escaping tests that descriptions and enum values survive
+// rendering into Go source unchanged.
+func TestRenderSchema_Escaping(t *testing.T) {
+	for i, s := range adversarialStrings {
+		s := s
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			enum := []interface{}{s, "other"}
+
+			schema := &openapi3.Schema{
+				Type: "object",
+				Properties: openapi3.Schemas{
+					"value": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Description: s,
+							Enum:        enum,
+						},
+					},
+				},
+			}
+
+			ts, diags := openapi.ConvertToTFSchema(
+				"Test", tf.NewTerrformScope("Test"), schema)
+			if diags.HasErrors() {
+				t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+			}
+
+			code, err := openapi.RenderSchema(ts)
+			if err != nil {
+				t.Fatalf("RenderSchema() error = %v\n%s", err, code)
+			}
+
+			file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+			if err != nil {
+				t.Fatalf("generated code does not parse: %v\n%s", err, code)
+			}
+
+			descriptions, enums := stringLiterals(t, file)
+
+			if s == "" {
+				if len(descriptions) != 0 {
+					t.Errorf("Description = %q, want none", descriptions)
+				}
+			} else if !reflect.DeepEqual(descriptions, []string{s}) {
+				t.Errorf("Description = %q, want %q", descriptions, s)
+			}
+
+			if !reflect.DeepEqual(enums, []string{s, "other"}) {
+				t.Errorf("enum = %q, want %q", enums, enum)
+			}
+		})
+	}
+}
+
+// stringLiterals returns the values of every Description field and of every
+// string slice literal in file.
+func stringLiterals(t *testing.T, file *ast.File) ([]string, []string) {
+	t.Helper()
+
+	var descriptions, enums []string
+
+	unquote := func(expr ast.Expr) string {
+		lit, ok := expr.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			t.Fatalf("expected a string literal, got %T", expr)
+		}
+
+		v, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			t.Fatalf("invalid string literal %s: %v", lit.Value, err)
+		}
+
+		return v
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.KeyValueExpr:
+			if key, ok := n.Key.(*ast.Ident); ok && key.Name == "Description" {
+				descriptions = append(descriptions, unquote(n.Value))
+			}
+		case *ast.CompositeLit:
+			if arr, ok := n.Type.(*ast.ArrayType); ok {
+				if elt, ok := arr.Elt.(*ast.Ident); ok && elt.Name == "string" {
+					for _, e := range n.Elts {
+						enums = append(enums, unquote(e))
+					}
+				}
+			}
+		}
+
+		return true
+	})
+
+	return descriptions, enums
+}
+
+const nestedOnlySpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /origins:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Origin'
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Origin'
+  /origins/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Origin'
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Origin:
+      type: object
+      properties:
+        id:
+          type: string
+          readOnly: true
+        hosts:
+          type: array
+          items:
+            $ref: '#/components/schemas/Host'
+    Host:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+// TestGenerateFiles_NestedOnly tests that schemas that are only nested blocks
+// get no documentation, examples or acceptance tests of their own.
+func TestGenerateFiles_NestedOnly(t *testing.T) {
+	files, diags, err := openapi.GenerateFiles(
+		strings.NewReader(nestedOnlySpec), "spec.yaml", "out",
+		openapi.Options{
+			AccTests:           true,
+			DocsFolderPath:     "docs",
+			ExamplesFolderPath: "examples",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	paths := make(map[string]bool, len(files))
+	for _, f := range files {
+		paths[filepath.ToSlash(f.Path)] = true
+	}
+
+	for _, want := range []string{
+		"out/origin_resource_test.go",
+		"docs/resources/origin.md",
+		"examples/resources/edgio_origin/resource.tf",
+		"out/host_schema.go",
+	} {
+		if !paths[want] {
+			t.Errorf("files = %v, want %s", paths, want)
+		}
+	}
+
+	for _, notWant := range []string{
+		"out/host_resource_test.go",
+		"docs/resources/host.md",
+		"examples/resources/edgio_host/resource.tf",
+	} {
+		if paths[notWant] {
+			t.Errorf("files = %v, want no %s", paths, notWant)
+		}
+	}
+}
+
+// TestCreateTFSchemaFromOpenAPI_FormatError tests that code which fails to
+// format is kept outside of the output folder, and that nothing is written to
+// it.
+func TestCreateTFSchemaFromOpenAPI_FormatError(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.yaml")
+	templates := filepath.Join(dir, "templates")
+	out := filepath.Join(dir, "out")
+
+	if err := os.WriteFile(spec, []byte(nestedOnlySpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(templates, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := os.WriteFile(filepath.Join(templates, "schema.go.tmpl"),
+		[]byte("package {{packageName}}\n\nfunc {\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = openapi.CreateTFSchemaFromOpenAPI(spec, out,
+		openapi.Options{TemplatesDir: templates})
+	if err == nil {
+		t.Fatal("CreateTFSchemaFromOpenAPI() expected an error")
+	}
+
+	_, kept, ok := strings.Cut(err.Error(), "unformatted code written to ")
+	if !ok {
+		t.Fatalf("error = %v, want the path of the unformatted code", err)
+	}
+
+	kept = strings.TrimSuffix(kept, ")")
+	t.Cleanup(func() { os.Remove(kept) })
+
+	code, readErr := os.ReadFile(kept)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if !strings.Contains(string(code), "func {") {
+		t.Errorf("kept code = %q, want the unformatted code", code)
+	}
+
+	if _, statErr := os.Stat(out); !os.IsNotExist(statErr) {
+		t.Errorf("output folder exists, want nothing written: %v", statErr)
+	}
+}
@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+)
+
+// document is an OpenAPI 3.0 document loaded from a source that may have been
+// written in another version.
+type document struct {
+	*openapi3.T
+	// sources maps pointers into the document to where they point in the
+	// source, for the parts that were moved while loading it.
+	sources map[string]string
+}
+
+// sourcePointer returns where a pointer into the document points in the
+// source.
+func (d *document) sourcePointer(pointer string) string {
+	return mapPointer(d.sources, pointer)
+}
+
+// loadDocument loads a document in JSON or YAML from data. filePath is the
+// file the document was read from, which external references are relative
+// to. Swagger 2.0 documents are upconverted and OpenAPI 3.1 documents
+// normalized to OpenAPI 3.0, and the constructs that don't survive either are
+// reported. External references are loaded from files under refRoot, which
+// defaults to the directory of the document.
+func loadDocument(
+	data []byte,
+	filePath string,
+	refRoot string,
+) (*document, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	fail := func(err error) internal.Diagnostics {
+		return append(diags, internal.Diagnostic{
+			Severity: internal.SeverityError,
+			Message:  err.Error(),
+			File:     filePath,
+		})
+	}
+
+	var version struct {
+		Swagger string `json:"swagger"`
+		OpenAPI string `json:"openapi"`
+	}
+
+	// Leave reporting syntax errors to the OpenAPI 3 loader.
+	_ = yaml.Unmarshal(data, &version)
+
+	if refRoot == "" {
+		refRoot = filepath.Dir(filePath)
+	}
+
+	root, err := filepath.Abs(refRoot)
+	if err != nil {
+		return nil, fail(err)
+	}
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = readFromRoot(root)
+	location := &url.URL{Path: filepath.ToSlash(filePath)}
+
+	switch {
+	case version.Swagger != "":
+		if version.Swagger != swaggerVersion {
+			diags.AddError(internal.JSONPointer("swagger"), fmt.Sprintf(
+				"Swagger version '%s' is not supported, only %s is",
+				version.Swagger, swaggerVersion))
+
+			return nil, diags
+		}
+
+		doc, swaggerDiags := loadSwagger(data, loader, location)
+		diags.Append(swaggerDiags)
+
+		if doc == nil {
+			return nil, diags
+		}
+
+		return &document{T: doc, sources: swaggerSources}, diags
+	case strings.HasPrefix(version.OpenAPI, "3.1"):
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fail(err)
+		}
+
+		sources, normalizeDiags := normalize31(raw)
+		diags.Append(normalizeDiags)
+
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		doc, err := loader.LoadFromDataWithPath(data, location)
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		return &document{T: doc, sources: sources}, diags
+	default:
+		doc, err := loader.LoadFromDataWithPath(data, location)
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		return &document{T: doc}, diags
+	}
+}
+
+// readFromRoot returns a ReadFromURIFunc that only reads files under root, so
+// that documents can't reach into the rest of the file system or the network.
+func readFromRoot(root string) openapi3.ReadFromURIFunc {
+	return func(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if location.Host != "" ||
+			(location.Scheme != "" && location.Scheme != "file") {
+			return nil, fmt.Errorf("reference to '%s' is not a local file; "+
+				"only files under '%s' are loaded", location, root)
+		}
+
+		path, err := filepath.Abs(location.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if rel, err := filepath.Rel(root, path); err != nil ||
+			rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("reference to '%s' escapes the root '%s'; "+
+				"set -ref-root to allow it", location.Path, root)
+		}
+
+		return os.ReadFile(path)
+	}
+}
+
+// mapPointer replaces the longest prefix of pointer that is a key of m.
+func mapPointer(m map[string]string, pointer string) string {
+	for prefix := pointer; prefix != "" && prefix != "#"; {
+		if to, ok := m[prefix]; ok {
+			return to + strings.TrimPrefix(pointer, prefix)
+		}
+
+		i := strings.LastIndex(prefix, "/")
+		if i < 0 {
+			break
+		}
+
+		prefix = prefix[:i]
+	}
+
+	return pointer
+}
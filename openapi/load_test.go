@@ -0,0 +1,127 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const externalSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        origin:
+          $ref: './schemas/origin.yaml#/Origin'
+`
+
+// writeFiles writes files, keyed by their path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOpenAPI3ToTerraform_ExternalRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"spec.yaml": externalSpec,
+		"schemas/origin.yaml": `Origin:
+  type: object
+  properties:
+    address:
+      $ref: ./address.yaml
+`,
+		"schemas/address.yaml": `type: object
+properties:
+  host:
+    type: string
+`,
+	})
+
+	scope, diags := openapi.OpenAPI3ToTerraform(
+		filepath.Join(dir, "spec.yaml"), openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	var names []string
+	for _, ts := range scope.Schemas {
+		names = append(names, ts.Name)
+	}
+
+	if got, want := strings.Join(names, ","), "Address,Origin,Rule"; got != want {
+		t.Errorf("schemas = %s, want %s", got, want)
+	}
+}
+
+func TestOpenAPI3ToTerraform_RefRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/spec.yaml": strings.ReplaceAll(
+			externalSpec, "./schemas/", "../schemas/"),
+		"schemas/origin.yaml": `Origin:
+  type: object
+  properties:
+    host:
+      type: string
+`,
+	})
+
+	path := filepath.Join(dir, "api", "spec.yaml")
+
+	_, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if !diags.HasErrors() || !strings.Contains(diags.Error(), "escapes the root") {
+		t.Errorf("OpenAPI3ToTerraform() diags = %v, want a ref escaping the root", diags)
+	}
+
+	_, diags = openapi.OpenAPI3ToTerraform(path, openapi.Options{RefRoot: dir})
+	if diags.HasErrors() {
+		t.Errorf("OpenAPI3ToTerraform(RefRoot) diags = %v", diags)
+	}
+}
+
+func TestOpenAPI3ToTerraformFromReader(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.3",
+  "info": {"title": "Test", "version": "1.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Rule": {"type": "object", "properties": {"id": {"type": "string"}}}
+    }
+  }
+}`
+
+	scope, diags := openapi.OpenAPI3ToTerraformFromReader(
+		strings.NewReader(spec), "<stdin>", openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraformFromReader() diags = %v", diags)
+	}
+
+	if len(scope.Schemas) != 1 {
+		t.Fatalf("Schemas = %+v, want Rule", scope.Schemas)
+	}
+
+	// The reserved id attribute is renamed, and located in the JSON input.
+	if len(diags) != 1 || diags[0].File != "<stdin>" || diags[0].Line != 7 {
+		t.Errorf("diags = %v, want a warning at <stdin>:7", diags)
+	}
+}
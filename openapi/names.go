@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// RenameStrategy decides what happens to properties whose attribute name is
+// reserved by Terraform or collides with another property once snake cased.
+type RenameStrategy string
+
+// Known rename strategies.
+const (
+	// RenamePrefix prefixes the attribute name with the schema name, e.g. id
+	// becomes origin_id.
+	RenamePrefix RenameStrategy = "prefix"
+	// RenameError reports the property as an error instead of renaming it.
+	RenameError RenameStrategy = "error"
+)
+
+// ParseRenameStrategy parses the name of a RenameStrategy.
+func ParseRenameStrategy(s string) (RenameStrategy, error) {
+	switch RenameStrategy(s) {
+	case RenamePrefix, RenameError:
+		return RenameStrategy(s), nil
+	default:
+		return "", fmt.Errorf("unknown rename strategy '%s'", s)
+	}
+}
+
+// attributeNames maps each property of an object schema to a unique, legal
+// Terraform attribute name. Reserved and colliding names are renamed with the
+// converter's strategy; properties that can't be named are left out. Terraform
+// only reserves names at the top level of a resource, so the names of blocks
+// declared inline, which are never resources, are only renamed when they
+// collide.
+func (c *converter) attributeNames(
+	schemaName string,
+	pointer string,
+	s *openapi3.Schema,
+	inline bool,
+) (map[string]string, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	propNames := sortedKeys(s.Properties)
+
+	names := make(map[string]string, len(propNames))
+	taken := make(map[string]bool, len(propNames))
+
+	// Properties that are already snake cased keep their name when they
+	// collide with one that isn't, so fooBar is renamed rather than foo_bar.
+	ordered := append([]string(nil), propNames...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return isSnakeCase(ordered[i]) && !isSnakeCase(ordered[j])
+	})
+
+	type conflict struct {
+		propName string
+		attr     string
+		reason   string
+	}
+
+	var conflicts []conflict
+
+	for _, propName := range ordered {
+		attr := internal.ToSnakeCase(propName)
+
+		switch {
+		case !inline && tf.IsReservedAttributeName(attr):
+			conflicts = append(conflicts, conflict{
+				propName, attr,
+				fmt.Sprintf("'%s' is reserved by Terraform", attr),
+			})
+		case !inline && attr == tf.IDAttributeName &&
+			!isComputedID(s, propName):
+			conflicts = append(conflicts, conflict{
+				propName, attr,
+				fmt.Sprintf("'%s' may only be a read-only string", attr),
+			})
+		case taken[attr]:
+			conflicts = append(conflicts, conflict{
+				propName, attr,
+				fmt.Sprintf("'%s' collides with another property", attr),
+			})
+		default:
+			names[propName] = attr
+			taken[attr] = true
+		}
+	}
+
+	// Rename conflicts once every other name is taken, so a renamed attribute
+	// never steals the name of a property that didn't need renaming.
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].propName < conflicts[j].propName
+	})
+
+	for _, cf := range conflicts {
+		propPointer := internal.ChildJSONPointer(
+			pointer, "properties", cf.propName)
+
+		if c.renameStrategy == RenameError {
+			diags.AddError(propPointer, fmt.Sprintf(
+				"attribute name %s; rename the property or use a rename "+
+					"strategy other than '%s'", cf.reason, RenameError))
+
+			continue
+		}
+
+		base := internal.ToSnakeCase(schemaName) + "_" + cf.attr
+
+		renamed := base
+		for i := 2; taken[renamed]; i++ {
+			renamed = fmt.Sprintf("%s_%d", base, i)
+		}
+
+		names[cf.propName] = renamed
+		taken[renamed] = true
+
+		diags.AddWarning(propPointer, fmt.Sprintf(
+			"attribute name %s; renamed to '%s'", cf.reason, renamed))
+	}
+
+	return names, diags
+}
+
+// isComputedID returns true if the named property of s is a read-only, optional
+// string, which the SDK accepts as the id attribute.
+func isComputedID(s *openapi3.Schema, propName string) bool {
+	ref := s.Properties[propName]
+
+	return ref != nil && ref.Value != nil && ref.Value.Type == "string" &&
+		ref.Value.ReadOnly && !isRequired(s, propName)
+}
+
+// isSnakeCase returns true if the name is unchanged by snake casing.
+func isSnakeCase(name string) bool {
+	return internal.ToSnakeCase(name) == name
+}
+
+// checkIdentifiers reports schemas whose names, as Go identifiers, collide
+// with that of another schema, as their generated functions and constants
+// would too.
+func checkIdentifiers(scope *tf.TerraformScope) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	taken := make(map[string]*tf.TerraformSchema)
+
+	for _, ts := range scope.Schemas {
+		for _, s := range append([]*tf.TerraformSchema{ts}, ts.InlineSchemas()...) {
+			other, ok := taken[s.NameCamelCase]
+			if !ok {
+				taken[s.NameCamelCase] = s
+				continue
+			}
+
+			diags.AddError(s.Pointer, fmt.Sprintf(
+				"schema '%s' has the same Go name '%s' as schema '%s'; "+
+					"rename one of them", s.Name, s.NameCamelCase, other.Name))
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,174 @@
+package openapi_test
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const goNamesSpec = `openapi: 3.0.3
+info:
+  title: 2nd Edge API v1.0
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    2FAConfig:
+      type: object
+      properties:
+        enabled:
+          type: boolean
+    type:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestOpenAPI3ToTerraform_GoNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(goNamesSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		packageName string
+		wantPackage string
+		wantErr     string
+	}{
+		{
+			name:        "from title",
+			wantPackage: "pkg2ndedgeapiv10",
+		},
+		{
+			name:        "override",
+			packageName: "edge_v1",
+			wantPackage: "edge_v1",
+		},
+		{
+			name:        "invalid override",
+			packageName: "edge-v1",
+			wantErr:     "package name 'edge-v1' is not a valid Go package name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, diags := openapi.OpenAPI3ToTerraform(path,
+				openapi.Options{PackageName: tt.packageName})
+
+			if tt.wantErr != "" {
+				if !diags.HasErrors() || diags[len(diags)-1].Message != tt.wantErr {
+					t.Errorf("diags = %v, want error %q", diags, tt.wantErr)
+				}
+
+				return
+			}
+
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+
+			for _, ts := range scope.Schemas {
+				code, err := openapi.RenderSchema(ts)
+				if err != nil {
+					t.Fatalf("RenderSchema(%s) error = %v", ts.Name, err)
+				}
+
+				file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+				if err != nil {
+					t.Fatalf("RenderSchema(%s) is not valid Go: %v", ts.Name, err)
+				}
+
+				if file.Name.Name != tt.wantPackage {
+					t.Errorf("package = %s, want %s", file.Name.Name, tt.wantPackage)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAPI3ToTerraform_GoNameCollision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	spec := goNamesSpec + `    Type:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+
+	var found bool
+
+	for _, d := range diags {
+		found = found || strings.Contains(d.Message, "same Go name 'Type'")
+	}
+
+	if !diags.HasErrors() || !found {
+		t.Errorf("diags = %v, want a Go name collision error", diags)
+	}
+}
+
+const inlineNamesSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        id:
+          type: string
+        settings:
+          type: object
+          properties:
+            id:
+              type: string
+            count:
+              type: integer
+`
+
+func TestOpenAPI3ToTerraform_InlineBlockNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(inlineNamesSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	// Only the top-level id is renamed.
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "'rule_id'") {
+		t.Errorf("diags = %v, want a warning renaming id to rule_id", diags)
+	}
+
+	if len(scope.Schemas) != 1 {
+		t.Fatalf("Schemas = %+v, want Rule", scope.Schemas)
+	}
+
+	settings := scope.Schemas[0].Properties["settings"]
+	if settings.Elem == nil || settings.Elem.Schema == nil {
+		t.Fatalf("settings = %+v, want a nested block", settings)
+	}
+
+	for _, name := range []string{"id", "count"} {
+		if _, ok := settings.Elem.Schema.Properties[name]; !ok {
+			t.Errorf("settings attributes = %v, want %s", settings.Elem.Schema.Properties, name)
+		}
+	}
+}
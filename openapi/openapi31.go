@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stevenpaz/tf-schema-gen/internal"
+)
+
+// openapi30Version is the version OpenAPI 3.1 documents are normalized to.
+const openapi30Version = "3.0.3"
+
+// normalizer rewrites a decoded OpenAPI 3.1 document into its OpenAPI 3.0
+// equivalent, so both versions share the same internal model and produce the
+// same output for equivalent schemas.
+type normalizer struct {
+	diags internal.Diagnostics
+	// components is the components/schemas object $defs are moved into.
+	components map[string]interface{}
+	// refs maps the pointers of moved $defs to their new references.
+	refs map[string]string
+	// sources maps the pointers of moved $defs back to the source.
+	sources map[string]string
+}
+
+// normalize31 rewrites a decoded OpenAPI 3.1 document in place and returns
+// where pointers into the rewritten document point in the source.
+func normalize31(doc map[string]interface{}) (map[string]string, internal.Diagnostics) {
+	components, _ := doc["components"].(map[string]interface{})
+	if components == nil {
+		components = make(map[string]interface{})
+		doc["components"] = components
+	}
+
+	schemas, _ := components["schemas"].(map[string]interface{})
+	if schemas == nil {
+		schemas = make(map[string]interface{})
+		components["schemas"] = schemas
+	}
+
+	n := &normalizer{
+		components: schemas,
+		refs:       make(map[string]string),
+		sources:    make(map[string]string),
+	}
+
+	doc["openapi"] = openapi30Version
+
+	for _, name := range sortedKeys(schemas) {
+		n.schema(internal.JSONPointer("components", "schemas", name), schemas[name])
+	}
+
+	for _, key := range sortedKeys(doc) {
+		if key != "components" {
+			n.walk(internal.JSONPointer(key), doc[key])
+		}
+	}
+
+	for _, key := range sortedKeys(components) {
+		if key != "schemas" {
+			n.walk(internal.JSONPointer("components", key), components[key])
+		}
+	}
+
+	if len(n.refs) > 0 {
+		n.rewriteRefs(doc)
+	}
+
+	return n.sources, n.diags
+}
+
+// walk finds the schemas in a part of the document that isn't a schema.
+func (n *normalizer) walk(pointer string, node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			child := internal.ChildJSONPointer(pointer, key)
+
+			switch key {
+			case "schema":
+				n.schema(child, v[key])
+			case "example", "examples":
+				// Examples are data, not part of the document.
+			default:
+				n.walk(child, v[key])
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			n.walk(internal.ChildJSONPointer(pointer, fmt.Sprint(i)), item)
+		}
+	}
+}
+
+// schema normalizes the schema at pointer and the schemas nested in it.
+func (n *normalizer) schema(pointer string, node interface{}) {
+	s, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	n.types(pointer, s)
+	normalizeExclusive(s, "exclusiveMinimum", "minimum", func(a, b float64) bool { return a >= b })
+	normalizeExclusive(s, "exclusiveMaximum", "maximum", func(a, b float64) bool { return a <= b })
+
+	if v, ok := s["const"]; ok {
+		s["enum"] = []interface{}{v}
+		delete(s, "const")
+	}
+
+	for _, key := range []string{"properties", "$defs"} {
+		children, _ := s[key].(map[string]interface{})
+		for _, name := range sortedKeys(children) {
+			n.schema(internal.ChildJSONPointer(pointer, key, name), children[name])
+		}
+	}
+
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		n.schema(internal.ChildJSONPointer(pointer, key), s[key])
+	}
+
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		children, _ := s[key].([]interface{})
+		for i, child := range children {
+			n.schema(internal.ChildJSONPointer(pointer, key, fmt.Sprint(i)), child)
+		}
+	}
+
+	if defs, ok := s["$defs"].(map[string]interface{}); ok {
+		n.moveDefs(pointer, defs)
+		delete(s, "$defs")
+	}
+}
+
+// types replaces a list of types with the single type OpenAPI 3.0 allows,
+// turning "null" into nullable.
+func (n *normalizer) types(pointer string, s map[string]interface{}) {
+	list, ok := s["type"].([]interface{})
+	if !ok {
+		return
+	}
+
+	var types []string
+
+	for _, t := range list {
+		if t == "null" {
+			s["nullable"] = true
+			continue
+		}
+
+		types = append(types, fmt.Sprint(t))
+	}
+
+	switch len(types) {
+	case 0:
+		delete(s, "type")
+	case 1:
+		s["type"] = types[0]
+	default:
+		n.diags.AddError(internal.ChildJSONPointer(pointer, "type"), fmt.Sprintf(
+			"types '%s' cannot be represented by a single Terraform type",
+			strings.Join(types, "', '")))
+
+		s["type"] = types[0]
+	}
+}
+
+// normalizeExclusive turns the numeric exclusive bound of JSON Schema 2020-12
+// into the bound and boolean flag of OpenAPI 3.0. stricter reports whether
+// the exclusive bound is at least as strict as the inclusive one.
+func normalizeExclusive(
+	s map[string]interface{},
+	exclusive, inclusive string,
+	stricter func(a, b float64) bool,
+) {
+	bound, ok := s[exclusive].(float64)
+	if !ok {
+		return
+	}
+
+	if current, ok := s[inclusive].(float64); ok && !stricter(bound, current) {
+		delete(s, exclusive)
+		return
+	}
+
+	s[inclusive] = bound
+	s[exclusive] = true
+}
+
+// moveDefs moves the $defs of the schema at pointer into the components, as
+// OpenAPI 3.0 has no other place for reusable schemas.
+func (n *normalizer) moveDefs(pointer string, defs map[string]interface{}) {
+	for _, name := range sortedKeys(defs) {
+		component := name
+		for i := 2; n.components[component] != nil; i++ {
+			component = fmt.Sprintf("%s%d", name, i)
+		}
+
+		n.components[component] = defs[name]
+
+		from := internal.ChildJSONPointer(pointer, "$defs", name)
+		to := internal.JSONPointer("components", "schemas", component)
+		n.refs[from] = to
+		n.sources[to] = from
+	}
+}
+
+// rewriteRefs points the references to moved $defs at their components.
+func (n *normalizer) rewriteRefs(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			v["$ref"] = mapPointer(n.refs, ref)
+		}
+
+		for _, child := range v {
+			n.rewriteRefs(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			n.rewriteRefs(child)
+		}
+	}
+}
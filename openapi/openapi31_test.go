@@ -0,0 +1,138 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const openapi30Spec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        name:
+          type: string
+          nullable: true
+        kind:
+          type: string
+          enum: [redirect]
+        priority:
+          type: integer
+          minimum: 0
+          exclusiveMinimum: true
+          maximum: 100
+        origin:
+          $ref: '#/components/schemas/Origin'
+    Origin:
+      type: object
+      properties:
+        host:
+          type: string
+`
+
+const openapi31Spec = `openapi: 3.1.0
+info:
+  title: Test
+  version: "1.0"
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        name:
+          type: [string, "null"]
+        kind:
+          const: redirect
+          type: string
+        priority:
+          type: integer
+          exclusiveMinimum: 0
+          maximum: 100
+        origin:
+          $ref: '#/components/schemas/Rule/$defs/Origin'
+      $defs:
+        Origin:
+          type: object
+          properties:
+            host:
+              type: string
+`
+
+// TestOpenAPI3ToTerraform_OpenAPI31 tests that equivalent OpenAPI 3.0 and 3.1
+// documents generate the same schemas.
+func TestOpenAPI3ToTerraform_OpenAPI31(t *testing.T) {
+	render := func(spec string) map[string]string {
+		path := filepath.Join(t.TempDir(), "spec.yaml")
+		if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+		if diags.HasErrors() {
+			t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+		}
+
+		code := make(map[string]string)
+
+		for _, ts := range scope.Schemas {
+			b, err := openapi.RenderSchema(ts)
+			if err != nil {
+				t.Fatalf("RenderSchema() error = %v", err)
+			}
+
+			code[ts.Name] = string(b)
+		}
+
+		return code
+	}
+
+	want := render(openapi30Spec)
+	got := render(openapi31Spec)
+
+	if len(got) != len(want) {
+		t.Fatalf("got schemas %v, want %v", len(got), len(want))
+	}
+
+	for name, code := range want {
+		if got[name] != code {
+			t.Errorf("schema %s = \n%s\nwant\n%s", name, got[name], code)
+		}
+	}
+}
+
+func TestOpenAPI3ToTerraform_OpenAPI31Types(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: Test
+  version: "1.0"
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        value:
+          type: [string, integer]
+`
+
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if !diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v, want an error", diags)
+	}
+
+	if d := diags[0]; d.Pointer != "#/components/schemas/Rule/properties/value/type" || d.Line != 11 {
+		t.Errorf("diagnostic = %v, want one at the type on line 11", d)
+	}
+}
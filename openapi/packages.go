@@ -0,0 +1,344 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// PackageGroup is a package the generated code is split into, which holds the
+// schemas used by the operations with any of its tags.
+type PackageGroup struct {
+	// Path is the folder of the package, relative to the output folder, e.g.
+	// internal/service/cdn. The package is named after its last element.
+	Path string
+	Tags []string
+}
+
+const (
+	// servicePackagesPath is the folder PackageByTag splits packages into.
+	servicePackagesPath = "internal/service"
+	// sharedPackagePath is the folder of the package split packages share.
+	sharedPackagePath = "internal/shared"
+)
+
+// packageGroups returns the packages the generated code is split into: the
+// configured groups, and with PackageByTag a package for every other tag.
+func packageGroups(doc *openapi3.T, opts Options) []PackageGroup {
+	groups := append([]PackageGroup(nil), opts.Groups...)
+	if !opts.PackageByTag {
+		return groups
+	}
+
+	grouped := make(map[string]bool)
+	for _, g := range groups {
+		for _, tag := range g.Tags {
+			grouped[tag] = true
+		}
+	}
+
+	tags := make(map[string]bool)
+	for _, tag := range doc.Tags {
+		tags[tag.Name] = true
+	}
+
+	for _, item := range doc.Paths {
+		for _, op := range item.Operations() {
+			for _, tag := range op.Tags {
+				tags[tag] = true
+			}
+		}
+	}
+
+	for _, tag := range sortedKeys(tags) {
+		if !grouped[tag] {
+			groups = append(groups, PackageGroup{
+				Path: path.Join(servicePackagesPath, internal.ToPackageName(tag)),
+				Tags: []string{tag},
+			})
+		}
+	}
+
+	return groups
+}
+
+// splitPackages assigns the schemas of scope to the packages the generated
+// code is split into. A schema is generated in the package of the tags of the
+// operations that use it, directly or through other schemas. Schemas no
+// package uses stay in the root package, and schemas more than one package
+// uses, the root package included, go in the shared package. Packages
+// therefore only ever import the shared package, and the root package the
+// others.
+func splitPackages(
+	scope *tf.TerraformScope,
+	doc *openapi3.T,
+	components map[string]*tf.TerraformSchema,
+	opts Options,
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	groups := packageGroups(doc, opts)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	if opts.ImportPath == "" {
+		diags.AddError("", "splitting the code into packages needs the "+
+			"import path of the output folder")
+
+		return diags
+	}
+
+	shared := &tf.Package{
+		Name:       path.Base(sharedPackagePath),
+		Path:       sharedPackagePath,
+		ImportPath: path.Join(opts.ImportPath, sharedPackagePath),
+		Shared:     true,
+	}
+
+	packages := make([]*tf.Package, 0, len(groups)+1)
+	paths := map[string]bool{shared.Path: true}
+
+	for _, g := range groups {
+		p := path.Clean(filepath.ToSlash(g.Path))
+		if p == "." || p == ".." || strings.HasPrefix(p, "../") ||
+			path.IsAbs(p) {
+			diags.AddError("", fmt.Sprintf(
+				"package path '%s' is not a folder in the output folder", g.Path))
+
+			continue
+		}
+
+		if paths[p] {
+			diags.AddError("", fmt.Sprintf(
+				"package path '%s' is used more than once", g.Path))
+
+			continue
+		}
+
+		paths[p] = true
+
+		packages = append(packages, &tf.Package{
+			Name:       internal.ToPackageName(path.Base(p)),
+			Path:       p,
+			ImportPath: path.Join(opts.ImportPath, p),
+			Tags:       g.Tags,
+		})
+	}
+
+	if diags.HasErrors() {
+		return diags
+	}
+
+	// owners holds the packages that use each schema, where nil is the root
+	// package.
+	owners := make(map[*tf.TerraformSchema]map[*tf.Package]bool)
+
+	own := func(ts *tf.TerraformSchema, p *tf.Package) bool {
+		if owners[ts] == nil {
+			owners[ts] = make(map[*tf.Package]bool)
+		}
+
+		if owners[ts][p] {
+			return false
+		}
+
+		owners[ts][p] = true
+
+		return true
+	}
+
+	for _, p := range packages {
+		for name := range reachableSchemas(doc, p.Tags) {
+			if ts := components[name]; ts != nil {
+				own(ts, p)
+			}
+		}
+	}
+
+	for _, ts := range scope.Schemas {
+		if len(owners[ts]) == 0 {
+			own(ts, nil)
+		}
+	}
+
+	// Schemas are also used by every package that uses a schema referencing
+	// them.
+	queue := append([]*tf.TerraformSchema(nil), scope.Schemas...)
+	for len(queue) > 0 {
+		ts := queue[0]
+		queue = queue[1:]
+
+		for _, ref := range referencedSchemas(ts) {
+			for p := range owners[ts] {
+				if own(ref, p) {
+					queue = append(queue, ref)
+				}
+			}
+		}
+	}
+
+	for _, ts := range scope.Schemas {
+		ts.Package = shared
+
+		if len(owners[ts]) == 1 {
+			for p := range owners[ts] {
+				ts.Package = p
+			}
+		}
+
+		for _, inline := range ts.InlineSchemas() {
+			inline.Package = ts.Package
+		}
+	}
+
+	scope.Packages = append(packages, shared)
+
+	for _, p := range packages {
+		if len(scope.SchemasIn(p)) == 0 {
+			diags.AddWarning("", fmt.Sprintf(
+				"no schemas are generated in package '%s'", p.Path))
+		}
+	}
+
+	return diags
+}
+
+// referencedSchemas returns the schemas of nested blocks of ts that are not
+// declared inline, including those of its inline nested blocks.
+func referencedSchemas(ts *tf.TerraformSchema) []*tf.TerraformSchema {
+	var refs []*tf.TerraformSchema
+
+	for _, s := range append([]*tf.TerraformSchema{ts}, ts.InlineSchemas()...) {
+		for _, prop := range s.Properties {
+			if isNestedBlock(prop) && !prop.Elem.Schema.Inline {
+				refs = append(refs, prop.Elem.Schema)
+			}
+		}
+	}
+
+	return refs
+}
+
+// packageDir returns the folder of a package relative to the output folder,
+// where nil is the root package.
+func packageDir(p *tf.Package) string {
+	if p == nil {
+		return ""
+	}
+
+	return filepath.FromSlash(p.Path)
+}
+
+// goFile is a Go file being rendered into a package of a scope. It resolves
+// references to the declarations of other packages, and records the imports
+// they need.
+type goFile struct {
+	scope *tf.TerraformScope
+	// pkg is the package of the file, or nil for the root package.
+	pkg *tf.Package
+	// imports maps the import paths the file needs to their package name.
+	imports map[string]string
+}
+
+func newGoFile(scope *tf.TerraformScope, pkg *tf.Package) *goFile {
+	return &goFile{scope: scope, pkg: pkg, imports: make(map[string]string)}
+}
+
+// qualifier returns the prefix that refers to the declarations of package p,
+// where nil is the root package.
+func (f *goFile) qualifier(p *tf.Package) string {
+	if p == nil || p == f.pkg {
+		return ""
+	}
+
+	f.imports[p.ImportPath] = p.Name
+
+	return p.Name + "."
+}
+
+// symbol returns how the file refers to name declared in package p. Names are
+// exported in the shared package, as other packages refer to them.
+func (f *goFile) symbol(p *tf.Package, name string) string {
+	if p != nil && p.Shared {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+
+	return f.qualifier(p) + name
+}
+
+// ref returns how the file refers to name declared in the package of ts.
+func (f *goFile) ref(ts *tf.TerraformSchema, name string) string {
+	return f.symbol(ts.Package, name)
+}
+
+// funcs returns the template functions that depend on the package of the file.
+func (f *goFile) funcs() template.FuncMap {
+	return template.FuncMap{
+		"packageName": func() string {
+			return f.scope.PackageName(f.pkg)
+		},
+		"qualify": func(ts *tf.TerraformSchema) string {
+			return f.qualifier(ts.Package)
+		},
+		"ref": f.ref,
+		"client": func(name string) string {
+			return f.symbol(f.scope.SharedPackage(), name)
+		},
+		"expandValue":         f.expandValue,
+		"flattenValue":        f.flattenValue,
+		"credentialAuthorize": f.credentialAuthorize,
+	}
+}
+
+// fileFuncs declare the template functions of goFile, so templates that use
+// them parse before they are bound to a file.
+var fileFuncs = newGoFile(nil, nil).funcs()
+
+// addImports adds the imports the file needs to its rendered code.
+func (f *goFile) addImports(code []byte) ([]byte, error) {
+	if len(f.imports) == 0 {
+		return code, nil
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "", code, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(f.imports))
+	for p := range f.imports {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		if name := f.imports[p]; name != path.Base(p) {
+			astutil.AddNamedImport(fset, file, name, p)
+		} else {
+			astutil.AddImport(fset, file, p)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
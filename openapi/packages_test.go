@@ -0,0 +1,284 @@
+package openapi_test
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const packagesSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /rules:
+    post:
+      tags: [cdn]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+  /rules/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      tags: [cdn]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+        "400":
+          description: bad request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+    delete:
+      tags: [cdn]
+      responses:
+        "204":
+          description: deleted
+  /firewalls:
+    get:
+      tags: [waf]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Firewall'
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        id:
+          type: string
+          readOnly: true
+        origin:
+          $ref: '#/components/schemas/Origin'
+    Firewall:
+      type: object
+      properties:
+        origins:
+          type: array
+          items:
+            $ref: '#/components/schemas/Origin'
+    Origin:
+      type: object
+      properties:
+        host:
+          type: string
+    Error:
+      type: object
+      properties:
+        message:
+          type: string
+    Property:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestOpenAPI3ToTerraform_Packages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(packagesSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts openapi.Options
+		// want maps schemas to the path of their package, where an empty
+		// path is the root package.
+		want map[string]string
+	}{
+		{
+			name: "flat",
+			opts: openapi.Options{},
+			want: map[string]string{
+				"Error": "", "Firewall": "", "Origin": "", "Property": "", "Rule": "",
+			},
+		},
+		{
+			name: "by tag",
+			opts: openapi.Options{PackageByTag: true},
+			want: map[string]string{
+				"Error":    "internal/service/cdn",
+				"Firewall": "internal/service/waf",
+				"Origin":   "internal/shared",
+				"Property": "",
+				"Rule":     "internal/service/cdn",
+			},
+		},
+		{
+			name: "groups",
+			opts: openapi.Options{Groups: []openapi.PackageGroup{
+				{Path: "services/edge", Tags: []string{"cdn", "waf"}},
+			}},
+			want: map[string]string{
+				"Error":    "services/edge",
+				"Firewall": "services/edge",
+				"Origin":   "services/edge",
+				"Property": "",
+				"Rule":     "services/edge",
+			},
+		},
+		{
+			name: "groups and tags",
+			opts: openapi.Options{
+				Groups: []openapi.PackageGroup{
+					{Path: "services/firewall", Tags: []string{"waf"}},
+				},
+				PackageByTag: true,
+			},
+			want: map[string]string{
+				"Error":    "internal/service/cdn",
+				"Firewall": "services/firewall",
+				"Origin":   "internal/shared",
+				"Property": "",
+				"Rule":     "internal/service/cdn",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ImportPath = "example.com/provider"
+
+			scope, diags := openapi.OpenAPI3ToTerraform(path, tt.opts)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+
+			got := make(map[string]string, len(scope.Schemas))
+			for _, ts := range scope.Schemas {
+				got[ts.Name] = ""
+				if ts.Package != nil {
+					got[ts.Name] = ts.Package.Path
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("packages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAPI3ToTerraform_PackagesNeedImportPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(packagesSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diags := openapi.OpenAPI3ToTerraform(path,
+		openapi.Options{PackageByTag: true})
+	if !diags.HasErrors() ||
+		!strings.Contains(diags[len(diags)-1].Message, "import path") {
+		t.Errorf("expected a missing import path error, got %v", diags)
+	}
+}
+
+func TestGenerateFiles_Packages(t *testing.T) {
+	out := t.TempDir()
+
+	files, diags, err := openapi.GenerateFiles(strings.NewReader(packagesSpec),
+		"spec.yaml", out, openapi.Options{
+			PackageByTag: true,
+			ImportPath:   "example.com/provider",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	// packages maps the folders of Go files to their package name, and
+	// imports to the imports of the files in them.
+	packages := make(map[string]string)
+	imports := make(map[string]map[string]bool)
+
+	for _, f := range files {
+		file, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Data,
+			parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir, err := filepath.Rel(out, filepath.Dir(f.Path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir = filepath.ToSlash(dir)
+
+		if name, ok := packages[dir]; ok && name != file.Name.Name {
+			t.Errorf("%s is in package %s, not %s", f.Path, file.Name.Name, name)
+		}
+
+		packages[dir] = file.Name.Name
+
+		if imports[dir] == nil {
+			imports[dir] = make(map[string]bool)
+		}
+
+		for _, spec := range file.Imports {
+			p, _ := strconv.Unquote(spec.Path.Value)
+			if strings.HasPrefix(p, "example.com/provider") {
+				imports[dir][p] = true
+			}
+		}
+	}
+
+	wantPackages := map[string]string{
+		".":                    "test",
+		"internal/service/cdn": "cdn",
+		"internal/service/waf": "waf",
+		"internal/shared":      "shared",
+	}
+
+	if !reflect.DeepEqual(packages, wantPackages) {
+		t.Errorf("packages = %v, want %v", packages, wantPackages)
+	}
+
+	wantImports := map[string]map[string]bool{
+		".": {
+			"example.com/provider/internal/service/cdn": true,
+			"example.com/provider/internal/shared":      true,
+		},
+		"internal/service/cdn": {"example.com/provider/internal/shared": true},
+		"internal/service/waf": {"example.com/provider/internal/shared": true},
+		"internal/shared":      {},
+	}
+
+	if !reflect.DeepEqual(imports, wantImports) {
+		t.Errorf("imports = %v, want %v", imports, wantImports)
+	}
+}
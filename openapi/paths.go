@@ -0,0 +1,592 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// mediaTypeJSON is the media type of request and response bodies resources
+// are generated for.
+const mediaTypeJSON = "application/json"
+
+// extAsync is the operation extension that marks an operation as
+// long-running, or not, regardless of its responses.
+const extAsync = "x-terraform-async"
+
+// extIDAttribute is the schema extension that names the property holding the
+// ID of a resource.
+const extIDAttribute = "x-terraform-id-attribute"
+
+// findResources finds the operations of the document that create, read,
+// update and delete each converted component schema, and sets them as the
+// schema's resource. Schemas missing any operation but update stay nested
+// block schemas.
+func findResources(
+	doc *openapi3.T,
+	components map[string]*tf.TerraformSchema,
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	found := make(map[string]*tf.ResourceOperations)
+
+	get := func(name string) *tf.ResourceOperations {
+		if found[name] == nil {
+			found[name] = &tf.ResourceOperations{}
+		}
+
+		return found[name]
+	}
+
+	// Delete operations don't name a schema, so they are matched to the read
+	// operation on the same path once every path has been seen.
+	deletes := make(map[string]*tf.Operation)
+	// bodies holds the request body schemas of create and update operations.
+	bodies := make(map[*tf.Operation]*openapi3.Schema)
+	// blocks holds the schemas of resources as they were converted.
+	blocks := make(map[*tf.TerraformSchema]*tf.TerraformSchema)
+
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		isItem := strings.HasSuffix(path, "}")
+
+		for _, method := range []string{
+			http.MethodPost, http.MethodGet, http.MethodPut, http.MethodPatch,
+			http.MethodDelete,
+		} {
+			op := item.GetOperation(method)
+			if op == nil {
+				continue
+			}
+
+			operation := &tf.Operation{
+				Method: method,
+				Path:   path,
+				Status: successStatus(op),
+				Async:  isAsync(op),
+			}
+
+			if method == http.MethodDelete {
+				if isItem {
+					deletes[path] = operation
+				}
+
+				continue
+			}
+
+			name, response, body := operationSchema(op, components)
+			if name == "" {
+				continue
+			}
+
+			operation.Response = response
+			bodies[operation] = body
+			ops := get(name)
+
+			switch {
+			case method == http.MethodPost && !isItem:
+				if ops.Create == nil {
+					ops.Create = operation
+				}
+			case method == http.MethodGet && isItem:
+				if ops.Read == nil {
+					ops.Read = operation
+				}
+			case (method == http.MethodPut || method == http.MethodPatch) &&
+				isItem:
+				// PUT is preferred, as the whole configuration is sent.
+				if ops.Update == nil || ops.Update.Method == http.MethodPatch {
+					ops.Update = operation
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(found) {
+		ts := components[name]
+		ops := found[name]
+
+		if ops.Read != nil {
+			ops.Delete = deletes[ops.Read.Path]
+		}
+
+		var missing []string
+
+		for _, op := range []struct {
+			name string
+			op   *tf.Operation
+		}{{"create", ops.Create}, {"read", ops.Read}, {"delete", ops.Delete}} {
+			if op.op == nil {
+				missing = append(missing, op.name)
+			}
+		}
+
+		if len(missing) > 0 {
+			diags.AddWarning(ts.Pointer, fmt.Sprintf(
+				"schema '%s' has no %s operation, so no resource is generated",
+				name, strings.Join(missing, " or ")))
+
+			continue
+		}
+
+		idField, idDiags := resourceIDField(doc, name, ts, ops)
+		if idDiags.HasErrors() {
+			diags.Append(idDiags)
+			continue
+		}
+
+		ops.IDField = idField
+
+		// The schema is fitted to its resource below, so it is kept as
+		// converted for where it is nested as a block.
+		nested := ts.Copy(ts.Name)
+
+		if paramDiags := mapPathParams(ts, ops); len(paramDiags) > 0 {
+			*ts = *nested
+			diags.Append(paramDiags)
+
+			continue
+		}
+
+		ts.Resource = ops
+		inferComputed(ts, bodies[ops.Create])
+		inferForceNew(ts, bodies[ops.Create], bodies[ops.Update])
+
+		blocks[ts] = nested
+	}
+
+	addBlockSchemas(components, blocks)
+
+	return diags
+}
+
+// addBlockSchemas adds the schemas resources had before they were fitted to
+// them to components, and makes the nested blocks of the resources' schemas
+// use them instead. Attributes only resources have, like path parameters, and
+// the ForceNew and Computed attributes inferred from their operations, don't
+// apply where they are nested. Schemas no block uses are left out.
+func addBlockSchemas(
+	components map[string]*tf.TerraformSchema,
+	blocks map[*tf.TerraformSchema]*tf.TerraformSchema,
+) {
+	names := make(map[*tf.TerraformSchema]string, len(blocks))
+
+	for _, name := range sortedKeys(components) {
+		ts := components[name]
+
+		nested, ok := blocks[ts]
+		if !ok {
+			continue
+		}
+
+		blockName := name + "Block"
+		for components[blockName] != nil {
+			blockName += "Block"
+		}
+
+		// Blocks may nest resources too, so they are all added before any
+		// nested block is changed.
+		blocks[ts] = nested.Copy(blockName)
+		components[blockName] = blocks[ts]
+		names[ts] = blockName
+	}
+
+	used := make(map[string]bool, len(names))
+
+	for _, name := range sortedKeys(components) {
+		ts := components[name]
+		if ts == nil {
+			continue
+		}
+
+		for _, s := range append([]*tf.TerraformSchema{ts}, ts.InlineSchemas()...) {
+			for attr, prop := range s.Properties {
+				if prop.Elem == nil || blocks[prop.Elem.Schema] == nil {
+					continue
+				}
+
+				used[names[prop.Elem.Schema]] = true
+
+				elem := *prop.Elem
+				elem.Schema = blocks[prop.Elem.Schema]
+				prop.Elem = &elem
+				s.Properties[attr] = prop
+			}
+		}
+	}
+
+	for _, name := range names {
+		if !used[name] {
+			delete(components, name)
+		}
+	}
+}
+
+// resourceIDField returns the name in API responses of the property that holds
+// the ID of a resource: the one the schema's x-terraform-id-attribute extension names,
+// or else the one named like the last parameter of the read operation's path,
+// or else id.
+func resourceIDField(
+	doc *openapi3.T,
+	name string,
+	ts *tf.TerraformSchema,
+	ops *tf.ResourceOperations,
+) (string, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	if ref := doc.Components.Schemas[name]; ref != nil && ref.Value != nil {
+		if hint, ok := ref.Value.Extensions[extIDAttribute]; ok {
+			field, isString := hint.(string)
+			attr, found := attributeOf(ts, field)
+
+			if !isString || !found {
+				diags.AddError(
+					internal.ChildJSONPointer(ts.Pointer, extIDAttribute),
+					fmt.Sprintf("%s of schema '%s' must name one of its "+
+						"properties", extIDAttribute, name))
+
+				return "", diags
+			}
+
+			return originalName(attr, ts.Properties[attr]), diags
+		}
+	}
+
+	if param := lastPathParam(ops.Read.Path); param != "" {
+		if attr, ok := attributeOf(ts, param); ok {
+			return originalName(attr, ts.Properties[attr]), diags
+		}
+	}
+
+	return "id", diags
+}
+
+// operationSchema returns the name of the converted component schema an
+// operation returns, or else sends, as JSON, an example of its response, and
+// the schema of its request body.
+func operationSchema(
+	op *openapi3.Operation,
+	components map[string]*tf.TerraformSchema,
+) (string, interface{}, *openapi3.Schema) {
+	var (
+		name string
+		body *openapi3.Schema
+	)
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		if mt := op.RequestBody.Value.Content.Get(mediaTypeJSON); mt != nil &&
+			mt.Schema != nil {
+			body = mt.Schema.Value
+
+			if components[componentName(mt.Schema.Ref)] != nil {
+				name = componentName(mt.Schema.Ref)
+			}
+		}
+	}
+
+	for _, status := range sortedKeys(op.Responses) {
+		resp := op.Responses[status]
+		if !strings.HasPrefix(status, "2") || resp.Value == nil {
+			continue
+		}
+
+		mt := resp.Value.Content.Get(mediaTypeJSON)
+		if mt == nil || mt.Schema == nil {
+			continue
+		}
+
+		// Create and update bodies may be schemas of their own, so the
+		// response decides which resource an operation belongs to.
+		if components[componentName(mt.Schema.Ref)] != nil {
+			return componentName(mt.Schema.Ref), mediaTypeExample(mt), body
+		}
+	}
+
+	return name, nil, body
+}
+
+// inferComputed makes the attributes of a resource that are optional in the
+// create request body, but required in the responses the schema describes,
+// Optional and Computed, as the API defaults them. Only the schema of the
+// resource is changed, not the copy where it is nested as a block.
+func inferComputed(ts *tf.TerraformSchema, create *openapi3.Schema) {
+	if create == nil {
+		return
+	}
+
+	sent := bodyProperties(create)
+	required := bodyRequired(create)
+
+	for _, name := range sortedKeys(ts.Properties) {
+		prop := ts.Properties[name]
+		if !prop.IsRequired() || !sent[prop.OriginalName] ||
+			required[prop.OriginalName] {
+			continue
+		}
+
+		prop.Required = nil
+		prop.SetOptional(true)
+		prop.SetComputed(true)
+		ts.Properties[name] = prop
+		ts.Decisions = append(ts.Decisions, tf.Decision{
+			Attribute: name,
+			Decision:  "Optional+Computed",
+			Reason: fmt.Sprintf("Optional when sent by `%s`, but always "+
+				"present in responses.", ts.Resource.Create),
+		})
+	}
+}
+
+// inferForceNew marks the attributes of a resource the API can't change once
+// it is created as ForceNew: every attribute if there is no update operation,
+// or else those sent on create but not on update. Nothing is inferred if a
+// request body is unknown. Only the schema of the resource is changed, not the
+// copy where it is nested as a block.
+func inferForceNew(ts *tf.TerraformSchema, create, update *openapi3.Schema) {
+	ops := ts.Resource
+
+	if create == nil || (ops.Update != nil && update == nil) {
+		return
+	}
+
+	created := bodyProperties(create)
+	updated := bodyProperties(update)
+
+	for _, name := range sortedKeys(ts.Properties) {
+		prop := ts.Properties[name]
+		if isReadOnly(prop) || !created[prop.OriginalName] ||
+			updated[prop.OriginalName] {
+			continue
+		}
+
+		reason := "The API has no update operation."
+		if ops.Update != nil {
+			reason = fmt.Sprintf("Sent by `%s`, but not by `%s`.",
+				ops.Create, ops.Update)
+		}
+
+		prop.ForceNew = true
+		ts.Properties[name] = prop
+		ts.Decisions = append(ts.Decisions, tf.Decision{
+			Attribute: name,
+			Decision:  "ForceNew",
+			Reason:    reason,
+		})
+	}
+}
+
+// bodyRequired returns the names of the required properties of a request body
+// schema, including those of the schemas it is composed of with allOf.
+func bodyRequired(s *openapi3.Schema) map[string]bool {
+	required := make(map[string]bool)
+
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	for _, ref := range s.AllOf {
+		if ref.Value != nil {
+			for name := range bodyRequired(ref.Value) {
+				required[name] = true
+			}
+		}
+	}
+
+	return required
+}
+
+// bodyProperties returns the names of the properties of a request body
+// schema, including those of the schemas it is composed of with allOf.
+func bodyProperties(s *openapi3.Schema) map[string]bool {
+	props := make(map[string]bool)
+	if s == nil {
+		return props
+	}
+
+	for name := range s.Properties {
+		props[name] = true
+	}
+
+	for _, ref := range s.AllOf {
+		if ref.Value != nil {
+			for name := range bodyProperties(ref.Value) {
+				props[name] = true
+			}
+		}
+	}
+
+	return props
+}
+
+// isAsync returns true if an operation is long-running: its x-terraform-async
+// extension is true, or it answers 202 Accepted, or it answers with a Location
+// header other than that of a created resource.
+func isAsync(op *openapi3.Operation) bool {
+	if async, ok := op.Extensions[extAsync].(bool); ok {
+		return async
+	}
+
+	for status, resp := range op.Responses {
+		if status == "202" {
+			return true
+		}
+
+		if !strings.HasPrefix(status, "2") || status == "201" ||
+			resp.Value == nil {
+			continue
+		}
+
+		for name := range resp.Value.Headers {
+			if strings.EqualFold(name, "Location") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// successStatus returns the first documented 2xx status code of an operation,
+// or 0 if there is none.
+func successStatus(op *openapi3.Operation) int {
+	for _, status := range sortedKeys(op.Responses) {
+		if code, err := strconv.Atoi(status); err == nil && code/100 == 2 {
+			return code
+		}
+	}
+
+	return 0
+}
+
+// mediaTypeExample returns the example of a media type, or the first of its
+// named examples.
+func mediaTypeExample(mt *openapi3.MediaType) interface{} {
+	if mt.Example != nil {
+		return mt.Example
+	}
+
+	for _, key := range sortedKeys(mt.Examples) {
+		if ex := mt.Examples[key]; ex != nil && ex.Value != nil {
+			return ex.Value.Value
+		}
+	}
+
+	if mt.Schema.Value != nil {
+		return schemaExample(mt.Schema.Value)
+	}
+
+	return nil
+}
+
+// mapPathParams maps the path parameters of every operation of a resource to
+// the attributes holding their values. The last parameter of item paths
+// identifies the resource. Parameters no property holds, like the ID of a
+// parent resource, get a required attribute of their own.
+func mapPathParams(
+	ts *tf.TerraformSchema,
+	ops *tf.ResourceOperations,
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	for _, op := range ops.Operations() {
+		op.Params = make(map[string]string)
+		idParam := lastPathParam(op.Path)
+
+		for _, param := range op.PathParams() {
+			if param == idParam {
+				op.Params[param] = ""
+				continue
+			}
+
+			attr, ok := attributeOf(ts, param)
+			if !ok {
+				attr, ok = addPathParam(ts, op, param)
+			}
+
+			if !ok {
+				diags.AddWarning(ts.Pointer, fmt.Sprintf(
+					"path parameter '%s' of %s is not a property of schema "+
+						"'%s', and its attribute name '%s' is taken, so no "+
+						"resource is generated",
+					param, op, ts.Name, internal.ToSnakeCase(param)))
+
+				continue
+			}
+
+			op.Params[param] = attr
+		}
+	}
+
+	return diags
+}
+
+// addPathParam adds a required attribute to a resource for a parameter of the
+// path of an operation that no property holds. The resource moves if the
+// parameter changes, so the attribute is ForceNew. It returns false if the
+// attribute name is taken.
+func addPathParam(
+	ts *tf.TerraformSchema,
+	op *tf.Operation,
+	param string,
+) (string, bool) {
+	attr := internal.ToSnakeCase(param)
+	if _, taken := ts.Properties[attr]; taken {
+		return "", false
+	}
+
+	prop := tf.NewTerraformProperty()
+	prop.Type = tf.TypeString
+	prop.SetRequired(true)
+	prop.SetDescription(fmt.Sprintf("The `%s` parameter of the API paths "+
+		"of the resource.", param))
+	prop.ForceNew = true
+	prop.PathParam = true
+	prop.OriginalName = param
+	prop.Pointer = internal.JSONPointer("paths", op.Path)
+
+	ts.AddProp(attr, prop)
+	ts.Decisions = append(ts.Decisions, tf.Decision{
+		Attribute: attr,
+		Decision:  "ForceNew",
+		Reason: fmt.Sprintf("A parameter of `%s` no property holds.",
+			op.Path),
+	})
+
+	return attr, true
+}
+
+// lastPathParam returns the parameter a path ends with, or an empty string.
+func lastPathParam(path string) string {
+	if !strings.HasSuffix(path, "}") {
+		return ""
+	}
+
+	return path[strings.LastIndex(path, "{")+1 : len(path)-1]
+}
+
+// attributeOf returns the attribute of the schema that holds the API property
+// name, or else the one whose API property is named the same once snake
+// cased, so a {rule_id} parameter finds a ruleId property. Renamed attributes
+// are matched by the name of their property, never by their new name.
+func attributeOf(ts *tf.TerraformSchema, name string) (string, bool) {
+	for attr, prop := range ts.Properties {
+		if originalName(attr, prop) == name {
+			return attr, true
+		}
+	}
+
+	for _, attr := range sortedKeys(ts.Properties) {
+		prop := ts.Properties[attr]
+		if internal.ToSnakeCase(originalName(attr, prop)) ==
+			internal.ToSnakeCase(name) {
+			return attr, true
+		}
+	}
+
+	return "", false
+}
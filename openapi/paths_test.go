@@ -0,0 +1,755 @@
+package openapi_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+const resourcesSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /properties/{property_id}/rules:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+              example:
+                id: r-1
+                property_id: p-1
+  /properties/{property_id}/rules/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+    patch:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "200":
+          description: ok
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "200":
+          description: ok
+    delete:
+      responses:
+        "204":
+          description: deleted
+  /origins:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Origin'
+      responses:
+        "201":
+          description: created
+components:
+  schemas:
+    Rule:
+      type: object
+      x-terraform-id-attribute: name
+      properties:
+        property_id:
+          type: string
+        name:
+          type: string
+    Origin:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestOpenAPI3ToTerraform_Resources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(resourcesSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	schemas := make(map[string]*tf.TerraformSchema)
+	for _, ts := range scope.Schemas {
+		schemas[ts.Name] = ts
+	}
+
+	item := "/properties/{property_id}/rules/{id}"
+	params := map[string]string{"property_id": "property_id", "id": ""}
+	want := &tf.ResourceOperations{
+		Create: &tf.Operation{
+			Method:   "POST",
+			Path:     "/properties/{property_id}/rules",
+			Params:   map[string]string{"property_id": "property_id"},
+			Status:   201,
+			Response: map[string]interface{}{"id": "r-1", "property_id": "p-1"},
+		},
+		Read:    &tf.Operation{Method: "GET", Path: item, Params: params, Status: 200},
+		Update:  &tf.Operation{Method: "PUT", Path: item, Params: params, Status: 200},
+		Delete:  &tf.Operation{Method: "DELETE", Path: item, Params: params, Status: 204},
+		IDField: "name",
+	}
+
+	if got := schemas["Rule"].Resource; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rule resource = %+v, want %+v", got, want)
+	}
+
+	if got := schemas["Origin"].Resource; got != nil {
+		t.Errorf("Origin resource = %+v, want nil", got)
+	}
+
+	if len(diags) != 1 {
+		t.Errorf("diags = %v, want a warning about Origin", diags)
+	}
+}
+
+// idFieldSpec is a spec with an Origin resource whose properties are
+// formatted in.
+const idFieldSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /origins:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Origin'
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Origin'
+  /origins/{origin_id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Origin'
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Origin:
+      type: object
+      properties:
+        name:
+          type: string
+%s
+`
+
+func TestOpenAPI3ToTerraform_IDField(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties string
+		want       string
+	}{
+		{
+			name: "read-only id",
+			properties: `        id:
+          type: string
+          readOnly: true`,
+			want: "id",
+		},
+		{
+			name: "renamed id",
+			properties: `        id:
+          type: string`,
+			want: "id",
+		},
+		{
+			name: "path parameter",
+			properties: `        originId:
+          type: string
+          readOnly: true`,
+			want: "originId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "spec.yaml")
+			spec := fmt.Sprintf(idFieldSpec, tt.properties)
+
+			if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+			if diags.HasErrors() {
+				t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+			}
+
+			ops := scope.Schemas[0].Resource
+			if ops == nil {
+				t.Fatalf("no resource; diags = %v", diags)
+			}
+
+			if ops.IDField != tt.want {
+				t.Errorf("IDField = %s, want %s", ops.IDField, tt.want)
+			}
+		})
+	}
+}
+
+const forceNewSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /rules:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+  /rules/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+    patch:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                pattern:
+                  type: string
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        id:
+          type: string
+          readOnly: true
+        name:
+          type: string
+        pattern:
+          type: string
+`
+
+func TestOpenAPI3ToTerraform_ForceNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(forceNewSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	ts := scope.Schemas[0]
+
+	forceNew := make(map[string]bool)
+	for name, prop := range ts.Properties {
+		forceNew[name] = prop.ForceNew
+	}
+
+	want := map[string]bool{"id": false, "name": true, "pattern": false}
+	if !reflect.DeepEqual(forceNew, want) {
+		t.Errorf("ForceNew = %v, want %v", forceNew, want)
+	}
+
+	wantDecisions := []tf.Decision{{
+		Attribute: "name",
+		Decision:  "ForceNew",
+		Reason:    "Sent by `POST /rules`, but not by `PATCH /rules/{id}`.",
+	}}
+	if !reflect.DeepEqual(ts.Decisions, wantDecisions) {
+		t.Errorf("Decisions = %+v, want %+v", ts.Decisions, wantDecisions)
+	}
+}
+
+// TestOpenAPI3ToTerraform_NestedForceNew tests that the attributes of a
+// resource inferred to be ForceNew aren't where its schema is nested.
+func TestOpenAPI3ToTerraform_NestedForceNew(t *testing.T) {
+	spec := forceNewSpec + `    Bundle:
+      type: object
+      properties:
+        rules:
+          type: array
+          items:
+            $ref: '#/components/schemas/Rule'
+`
+
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	schemas := make(map[string]*tf.TerraformSchema, len(scope.Schemas))
+	for _, ts := range scope.Schemas {
+		schemas[ts.Name] = ts
+	}
+
+	if !schemas["Rule"].Properties["name"].ForceNew {
+		t.Errorf("Rule name is not ForceNew")
+	}
+
+	block := schemas["Bundle"].Properties["rules"].Elem.Schema
+	if block.Properties["name"].ForceNew {
+		t.Errorf("name is ForceNew in the rules block")
+	}
+
+	if len(block.Decisions) != 0 {
+		t.Errorf("rules block Decisions = %+v, want none", block.Decisions)
+	}
+}
+
+const parentParamSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /properties/{property_id}/rules:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+  /properties/{property_id}/rules/{rule_id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        id:
+          type: string
+          readOnly: true
+        name:
+          type: string
+        port:
+          type: integer
+          minimum: 1
+`
+
+func TestOpenAPI3ToTerraform_ParentPathParams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(parentParamSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	ts := scope.Schemas[0]
+	if ts.Resource == nil {
+		t.Fatalf("no resource; diags = %v", diags)
+	}
+
+	prop := ts.Properties["property_id"]
+	if !prop.IsRequired() || !prop.ForceNew || !prop.PathParam ||
+		prop.Type != tf.TypeString {
+		t.Errorf("property_id = %+v, want a required, ForceNew string "+
+			"path parameter", prop)
+	}
+
+	want := []string{"property_id"}
+	if got := ts.Resource.ImportAttributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportAttributes() = %v, want %v", got, want)
+	}
+
+	expand, err := openapi.RenderExpand(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(expand), `out["property_id"]`) {
+		t.Errorf("expand sends the path parameter in bodies:\n%s", expand)
+	}
+
+	unitTest, err := openapi.RenderResourceTest(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := `d.Set("property_id", "test-1")`
+	if !strings.Contains(string(unitTest), set) {
+		t.Errorf("unit test = %s, want it to contain %s", unitTest, set)
+	}
+}
+
+// nestedResourceSpec nests the resource of parentParamSpec in another schema.
+const nestedResourceSpec = parentParamSpec + `    Bundle:
+      type: object
+      properties:
+        rules:
+          type: array
+          items:
+            $ref: '#/components/schemas/Rule'
+`
+
+// TestOpenAPI3ToTerraform_NestedResource tests that the attributes added to a
+// resource for its path parameters stay out of where its schema is nested.
+func TestOpenAPI3ToTerraform_NestedResource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(nestedResourceSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	schemas := make(map[string]*tf.TerraformSchema, len(scope.Schemas))
+	for _, ts := range scope.Schemas {
+		schemas[ts.Name] = ts
+	}
+
+	rule := schemas["Rule"]
+	if rule == nil || rule.Resource == nil {
+		t.Fatalf("no Rule resource; diags = %v", diags)
+	}
+
+	if _, ok := rule.Properties["property_id"]; !ok {
+		t.Errorf("Rule has no property_id attribute")
+	}
+
+	block := schemas["Bundle"].Properties["rules"].Elem.Schema
+	if block != schemas["RuleBlock"] || block.Resource != nil {
+		t.Fatalf("rules block = %+v, want the RuleBlock schema", block)
+	}
+
+	if _, ok := block.Properties["property_id"]; ok {
+		t.Errorf("rules block has the property_id path parameter")
+	}
+
+	code, err := openapi.RenderSchema(schemas["Bundle"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(code), "GetRuleBlockSchema()") {
+		t.Errorf("Bundle schema =\n%s\nwant the rules block to use "+
+			"GetRuleBlockSchema()", code)
+	}
+}
+
+const computedSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /rules:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                priority:
+                  type: integer
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+  /rules/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "200":
+          description: ok
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Rule:
+      type: object
+      required: [name, priority]
+      properties:
+        name:
+          type: string
+        priority:
+          type: integer
+`
+
+func TestOpenAPI3ToTerraform_Computed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(computedSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	ts := scope.Schemas[0]
+
+	type flags struct{ Required, Optional, Computed bool }
+
+	got := make(map[string]flags)
+	for name, prop := range ts.Properties {
+		got[name] = flags{prop.IsRequired(), prop.IsOptional(), prop.IsComputed()}
+	}
+
+	want := map[string]flags{
+		"name":     {Required: true},
+		"priority": {Optional: true, Computed: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flags = %+v, want %+v", got, want)
+	}
+
+	wantDecisions := []tf.Decision{{
+		Attribute: "priority",
+		Decision:  "Optional+Computed",
+		Reason:    "Optional when sent by `POST /rules`, but always present in responses.",
+	}}
+	if !reflect.DeepEqual(ts.Decisions, wantDecisions) {
+		t.Errorf("Decisions = %+v, want %+v", ts.Decisions, wantDecisions)
+	}
+}
+
+const asyncSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /rules:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "202":
+          description: accepted
+  /rules/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Rule'
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rule'
+      responses:
+        "200":
+          description: ok
+          headers:
+            Location:
+              schema:
+                type: string
+    delete:
+      x-terraform-async: false
+      responses:
+        "202":
+          description: accepted
+components:
+  schemas:
+    Rule:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestOpenAPI3ToTerraform_Async(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(asyncSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	ops := scope.Schemas[0].Resource
+
+	got := map[string]bool{
+		"create": ops.Create.Async,
+		"read":   ops.Read.Async,
+		"update": ops.Update.Async,
+		"delete": ops.Delete.Async,
+	}
+	want := map[string]bool{
+		"create": true,
+		"read":   false,
+		"update": true,
+		"delete": false,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Async = %v, want %v", got, want)
+	}
+}
+
+// TestOpenAPI3ToTerraform_NestedComputed tests that the attributes of a
+// resource inferred to be Optional and Computed stay required where its
+// schema is nested.
+func TestOpenAPI3ToTerraform_NestedComputed(t *testing.T) {
+	spec := computedSpec + `    Bundle:
+      type: object
+      properties:
+        rules:
+          type: array
+          items:
+            $ref: '#/components/schemas/Rule'
+`
+
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	schemas := make(map[string]*tf.TerraformSchema, len(scope.Schemas))
+	for _, ts := range scope.Schemas {
+		schemas[ts.Name] = ts
+	}
+
+	if prop := schemas["Rule"].Properties["priority"]; !prop.IsComputed() {
+		t.Errorf("Rule priority is not Computed")
+	}
+
+	block := schemas["Bundle"].Properties["rules"].Elem.Schema
+	if prop := block.Properties["priority"]; !prop.IsRequired() ||
+		prop.IsComputed() {
+		t.Errorf("priority in the rules block = %+v, want it required", prop)
+	}
+}
@@ -0,0 +1,215 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// providerFileName is the name of the file the provider is generated into.
+const providerFileName = "provider_schema.go"
+
+// baseURLAttribute is the provider attribute that holds the URL of the API.
+const baseURLAttribute = "base_url"
+
+// convertProvider converts the servers and security schemes of a document to
+// the provider block.
+func convertProvider(doc *openapi3.T) (*tf.Provider, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	p := &tf.Provider{BaseURL: tf.ProviderAttribute{
+		Name:     baseURLAttribute,
+		Required: true,
+	}}
+	p.BaseURL.Description = fmt.Sprintf("The URL of the API. Defaults to "+
+		"the `%s` environment variable.", p.BaseURL.EnvVar())
+
+	// The first server is the default, like in the documentation tools.
+	if len(doc.Servers) > 0 {
+		p.BaseURL.Required = false
+		p.BaseURL.Default = serverURL(doc.Servers[0])
+		p.BaseURL.Description = fmt.Sprintf("The URL of the API. Defaults "+
+			"to the `%s` environment variable, or `%s`.",
+			p.BaseURL.EnvVar(), p.BaseURL.Default)
+	}
+
+	names := map[string]bool{baseURLAttribute: true}
+
+	// attribute returns an attribute named name, or prefixed with the name
+	// of the scheme if another scheme already declares it.
+	attribute := func(scheme, name, desc string, sensitive bool) tf.ProviderAttribute {
+		if names[name] {
+			name = internal.ToSnakeCase(scheme) + "_" + name
+		}
+
+		names[name] = true
+
+		return tf.ProviderAttribute{
+			Name:        name,
+			Description: desc,
+			Sensitive:   sensitive,
+		}
+	}
+
+	if doc.Components.SecuritySchemes == nil {
+		return p, diags
+	}
+
+	for _, name := range sortedKeys(doc.Components.SecuritySchemes) {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+
+		s := ref.Value
+		pointer := internal.JSONPointer(
+			"components", "securitySchemes", name)
+
+		var c tf.Credential
+
+		switch {
+		case s.Type == "http" && strings.EqualFold(s.Scheme, "bearer"):
+			c.Type = tf.CredentialBearer
+			c.Attributes = []tf.ProviderAttribute{
+				attribute(name, "api_token",
+					"The token requests are authenticated with.", true),
+			}
+		case s.Type == "http" && strings.EqualFold(s.Scheme, "basic"):
+			c.Type = tf.CredentialBasic
+			c.Attributes = []tf.ProviderAttribute{
+				attribute(name, "username",
+					"The user requests are authenticated as.", false),
+				attribute(name, "password",
+					"The password of the user.", true),
+			}
+		case s.Type == "apiKey":
+			c.Type = tf.CredentialAPIKey
+			c.In = s.In
+			c.Name = s.Name
+			c.Attributes = []tf.ProviderAttribute{
+				attribute(name, internal.ToSnakeCase(name),
+					fmt.Sprintf("The API key sent in the %s %s.",
+						s.Name, s.In), true),
+			}
+		case s.Type == "oauth2" && s.Flows != nil &&
+			s.Flows.ClientCredentials != nil:
+			flow := s.Flows.ClientCredentials
+			c.Type = tf.CredentialClientCredentials
+			c.TokenURL = flow.TokenURL
+			c.Scopes = sortedKeys(flow.Scopes)
+			c.Attributes = []tf.ProviderAttribute{
+				attribute(name, "client_id",
+					"The ID of the OAuth 2.0 client.", false),
+				attribute(name, "client_secret",
+					"The secret of the OAuth 2.0 client.", true),
+			}
+		default:
+			diags.AddWarning(pointer, fmt.Sprintf(
+				"security scheme '%s' is not supported and is skipped", name))
+
+			continue
+		}
+
+		for i := range c.Attributes {
+			attr := &c.Attributes[i]
+			if s.Description != "" {
+				attr.Description += " " + s.Description
+			}
+
+			attr.Description += fmt.Sprintf(
+				" Defaults to the `%s` environment variable.", attr.EnvVar())
+		}
+
+		p.Credentials = append(p.Credentials, c)
+	}
+
+	return p, diags
+}
+
+// serverURL returns the URL of a server with its variables set to their
+// defaults.
+func serverURL(s *openapi3.Server) string {
+	url := s.URL
+	for name, v := range s.Variables {
+		if v != nil {
+			url = strings.ReplaceAll(url, "{"+name+"}", v.Default)
+		}
+	}
+
+	return strings.TrimSuffix(url, "/")
+}
+
+// RenderProvider renders the provider of a scope, with its schema and every
+// resource of the scope.
+func RenderProvider(scope *tf.TerraformScope) ([]byte, error) {
+	return builtinRenderer.provider(scope)
+}
+
+// provider is RenderProvider with the templates of r.
+func (r *renderer) provider(scope *tf.TerraformScope) ([]byte, error) {
+	var resources []*tf.TerraformSchema
+
+	for _, ts := range scope.Schemas {
+		if ts.Resource != nil {
+			resources = append(resources, ts)
+		}
+	}
+
+	return r.renderGo(providerTemplateName, newGoFile(scope, nil), map[string]interface{}{
+		"ProviderName": tf.ProviderName,
+		"Provider":     scope.Provider,
+		"Resources":    resources,
+	})
+}
+
+// credentialSet returns the Go expression that is true if every attribute of
+// a credential is set.
+func credentialSet(c tf.Credential) string {
+	conds := make([]string, 0, len(c.Attributes))
+	for _, attr := range c.Attributes {
+		conds = append(conds, fmt.Sprintf("d.Get(%s).(string) != %q",
+			internal.QuoteGoString(attr.Name), ""))
+	}
+
+	return strings.Join(conds, " && ")
+}
+
+// credentialAuthorize returns the Go expression of the function that
+// authenticates requests with a credential.
+func (f *goFile) credentialAuthorize(c tf.Credential) string {
+	args := make([]string, 0, len(c.Attributes)+3)
+
+	switch c.Type {
+	case tf.CredentialAPIKey:
+		args = append(args, internal.QuoteGoString(c.In),
+			internal.QuoteGoString(c.Name))
+	case tf.CredentialClientCredentials:
+		scopes := make([]string, 0, len(c.Scopes))
+		for _, scope := range c.Scopes {
+			scopes = append(scopes, internal.QuoteGoString(scope))
+		}
+
+		args = append(args, internal.QuoteGoString(c.TokenURL),
+			"[]string{"+strings.Join(scopes, ", ")+"}")
+	}
+
+	for _, attr := range c.Attributes {
+		args = append(args, fmt.Sprintf("d.Get(%s).(string)",
+			internal.QuoteGoString(attr.Name)))
+	}
+
+	return fmt.Sprintf("%s(%s)", f.symbol(f.scope.SharedPackage(), authorizeFuncs[c.Type]),
+		strings.Join(args, ", "))
+}
+
+// authorizeFuncs are the client functions that authenticate requests with
+// each type of credential.
+var authorizeFuncs = map[string]string{
+	tf.CredentialBearer:            "authorizeBearer",
+	tf.CredentialBasic:             "authorizeBasic",
+	tf.CredentialAPIKey:            "authorizeAPIKey",
+	tf.CredentialClientCredentials: "authorizeClientCredentials",
+}
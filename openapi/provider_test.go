@@ -0,0 +1,111 @@
+package openapi_test
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+const providerSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+servers:
+  - url: https://{region}.example.com/v1/
+    variables:
+      region:
+        default: eu
+paths: {}
+components:
+  securitySchemes:
+    token:
+      type: http
+      scheme: bearer
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://id.example.com/token
+          scopes:
+            write: Write access
+    oidc:
+      type: openIdConnect
+      openIdConnectUrl: https://id.example.com
+`
+
+func TestOpenAPI3ToTerraform_Provider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(providerSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	if len(diags) != 1 {
+		t.Errorf("diags = %v, want a warning about oidc", diags)
+	}
+
+	want := &tf.Provider{
+		BaseURL: tf.ProviderAttribute{
+			Name: "base_url",
+			Description: "The URL of the API. Defaults to the " +
+				"`EDGIO_BASE_URL` environment variable, or " +
+				"`https://eu.example.com/v1`.",
+			Default: "https://eu.example.com/v1",
+		},
+		Credentials: []tf.Credential{
+			{
+				Type: tf.CredentialClientCredentials,
+				Attributes: []tf.ProviderAttribute{
+					{
+						Name: "client_id",
+						Description: "The ID of the OAuth 2.0 client. " +
+							"Defaults to the `EDGIO_CLIENT_ID` environment " +
+							"variable.",
+					},
+					{
+						Name: "client_secret",
+						Description: "The secret of the OAuth 2.0 client. " +
+							"Defaults to the `EDGIO_CLIENT_SECRET` " +
+							"environment variable.",
+						Sensitive: true,
+					},
+				},
+				TokenURL: "https://id.example.com/token",
+				Scopes:   []string{"write"},
+			},
+			{
+				Type: tf.CredentialBearer,
+				Attributes: []tf.ProviderAttribute{{
+					Name: "api_token",
+					Description: "The token requests are authenticated " +
+						"with. Defaults to the `EDGIO_API_TOKEN` " +
+						"environment variable.",
+					Sensitive: true,
+				}},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(scope.Provider, want) {
+		t.Errorf("Provider = %+v, want %+v", scope.Provider, want)
+	}
+
+	code, err := openapi.RenderProvider(scope)
+	if err != nil {
+		t.Fatalf("RenderProvider() error = %v\n%s", err, code)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		t.Errorf("generated code does not parse: %v\n%s", err, code)
+	}
+}
@@ -0,0 +1,44 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// RenderReport renders a Markdown report of the decisions inferred about every
+// schema of a scope, for reviewers to confirm.
+func RenderReport(scope *tf.TerraformScope) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# Generation report for %s\n\n", scope.Name)
+	buf.WriteString("Decisions inferred from the OpenAPI document. " +
+		"Confirm them before releasing the provider.\n")
+
+	decided := false
+
+	for _, ts := range scope.Schemas {
+		if len(ts.Decisions) == 0 {
+			continue
+		}
+
+		decided = true
+
+		fmt.Fprintf(&buf, "\n## %s\n\n", ts.ResourceName())
+		buf.WriteString("| Attribute | Decision | Reason |\n")
+		buf.WriteString("| --- | --- | --- |\n")
+
+		for _, d := range ts.Decisions {
+			fmt.Fprintf(&buf, "| `%s` | %s | %s |\n", d.Attribute,
+				d.Decision, strings.ReplaceAll(d.Reason, "|", `\|`))
+		}
+	}
+
+	if !decided {
+		buf.WriteString("\nNo decisions were inferred.\n")
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,33 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+func TestRenderReport(t *testing.T) {
+	scope := tf.NewTerrformScope("Test")
+
+	rule := tf.NewTerrformSchema("Rule", scope)
+	rule.Decisions = []tf.Decision{{
+		Attribute: "name",
+		Decision:  "ForceNew",
+		Reason:    "Sent by `POST /rules`, but not by `PATCH /rules/{id}`.",
+	}}
+	scope.AddSchema(rule)
+	scope.AddSchema(tf.NewTerrformSchema("Origin", scope))
+
+	want := "# Generation report for Test\n\n" +
+		"Decisions inferred from the OpenAPI document. " +
+		"Confirm them before releasing the provider.\n\n" +
+		"## edgio_rule\n\n" +
+		"| Attribute | Decision | Reason |\n" +
+		"| --- | --- | --- |\n" +
+		"| `name` | ForceNew | Sent by `POST /rules`, but not by `PATCH /rules/{id}`. |\n"
+
+	if got := string(openapi.RenderReport(scope)); got != want {
+		t.Errorf("RenderReport() =\n%s\nwant\n%s", got, want)
+	}
+}
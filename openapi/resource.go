@@ -0,0 +1,408 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// Names of the files shared by generated resources.
+const (
+	clientFileName              = "client.go"
+	resourceHelpersFileName     = "resource_helpers.go"
+	resourceTestHelpersFileName = "resource_helpers_test.go"
+	asyncFileName               = "async.go"
+)
+
+// resourceFuncs are the functions available to resource templates.
+var resourceFuncs = template.FuncMap{
+	"readOnly": isReadOnly,
+	"pathExpr": pathExpr,
+	"method":   methodExpr,
+	// Provider templates.
+	"credentialSet": credentialSet,
+}
+
+// resourceFileName returns the name of the file the resource of a schema is
+// generated into.
+func resourceFileName(ts *tf.TerraformSchema) string {
+	return ts.NameSnakeCase + "_resource.go"
+}
+
+// expandFileName returns the name of the file the expand and flatten
+// functions of a schema are generated into.
+func expandFileName(ts *tf.TerraformSchema) string {
+	return ts.NameSnakeCase + "_expand.go"
+}
+
+// resourceTestFileName returns the name of the unit test of the resource of a
+// schema.
+func resourceTestFileName(ts *tf.TerraformSchema) string {
+	return ts.NameSnakeCase + "_resource_unit_test.go"
+}
+
+// RenderResource renders the resource of a schema with its CRUD functions.
+func RenderResource(ts *tf.TerraformSchema) ([]byte, error) {
+	return builtinRenderer.resource(ts)
+}
+
+// resource is RenderResource with the templates of r.
+func (r *renderer) resource(ts *tf.TerraformSchema) ([]byte, error) {
+	return r.renderGo(resourceTemplateName, newGoFile(ts.Scope, ts.Package), ts)
+}
+
+// RenderExpand renders the functions that convert a schema, and the nested
+// blocks declared inline in it, between attributes and API payloads.
+func RenderExpand(ts *tf.TerraformSchema) ([]byte, error) {
+	return builtinRenderer.expand(ts)
+}
+
+// expand is RenderExpand with the templates of r.
+func (r *renderer) expand(ts *tf.TerraformSchema) ([]byte, error) {
+	file := newGoFile(ts.Scope, ts.Package)
+
+	return r.renderGo(expandTemplateName, file, map[string]interface{}{
+		"Schemas": append([]*tf.TerraformSchema{ts}, ts.InlineSchemas()...),
+	})
+}
+
+// RenderClient renders the API client generated resources use, in the shared
+// package if the code of the scope is split into packages.
+func RenderClient(scope *tf.TerraformScope) ([]byte, error) {
+	return builtinRenderer.renderGo(clientTemplateName,
+		newGoFile(scope, scope.SharedPackage()), scope)
+}
+
+// RenderResourceHelpers renders the helpers generated resources share.
+func RenderResourceHelpers(scope *tf.TerraformScope) ([]byte, error) {
+	return builtinRenderer.renderGo(resourceHelpersTemplateName,
+		newGoFile(scope, nil), scope)
+}
+
+// RenderAsync renders the helpers resources with long-running operations use
+// to wait for them.
+func RenderAsync(scope *tf.TerraformScope) ([]byte, error) {
+	return builtinRenderer.renderGo(asyncTemplateName,
+		newGoFile(scope, nil), scope)
+}
+
+// RenderResourceTestHelpers renders the helpers the unit tests of generated
+// resources share.
+func RenderResourceTestHelpers(scope *tf.TerraformScope) ([]byte, error) {
+	return builtinRenderer.renderGo(resourceTestHelpersTemplateName,
+		newGoFile(scope, nil), scope)
+}
+
+// testRoute is an operation of the API stand-in of a generated unit test.
+type testRoute struct {
+	*tf.Operation
+	Status int
+	Body   string
+	// Location is the status URL of long-running operations.
+	Location string
+}
+
+// RenderResourceTest renders the unit tests of the resource of a schema. They
+// run the CRUD functions against a stand-in for the API that answers with the
+// response examples of the document.
+func RenderResourceTest(ts *tf.TerraformSchema) ([]byte, error) {
+	return builtinRenderer.resourceTest(ts)
+}
+
+// resourceTest is RenderResourceTest with the templates of r.
+func (r *renderer) resourceTest(ts *tf.TerraformSchema) ([]byte, error) {
+	payload, err := json.Marshal(testPayload(ts))
+	if err != nil {
+		return nil, fmt.Errorf("error encoding test payload: %w", err)
+	}
+
+	var (
+		routes   []testRoute
+		requests []string
+	)
+
+	ops := ts.Resource
+
+	for _, op := range ops.Operations() {
+		route := testRoute{Operation: op, Status: op.Status}
+
+		switch {
+		case op.Async:
+			// Only accepted operations are polled.
+			route.Status = http.StatusAccepted
+		case route.Status != 0:
+		case op.Method == http.MethodDelete:
+			route.Status = http.StatusNoContent
+		default:
+			route.Status = http.StatusOK
+		}
+
+		if op.Method != http.MethodDelete {
+			body, err := json.Marshal(testResponse(ts, op))
+			if err != nil {
+				return nil, fmt.Errorf("error encoding test response: %w", err)
+			}
+
+			route.Body = string(body)
+		}
+
+		routes = append(routes, route)
+		requests = append(requests, op.String())
+
+		if !op.Async {
+			continue
+		}
+
+		// Long-running operations are polled until they are done, and the
+		// resource is read afterwards.
+		body, err := json.Marshal(map[string]interface{}{
+			"status": "succeeded",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding test status: %w", err)
+		}
+
+		status := testRoute{
+			Operation: &tf.Operation{
+				Method: http.MethodGet,
+				Path:   "/test-operations/" + strings.ToLower(op.Method),
+			},
+			Status: http.StatusOK,
+			Body:   string(body),
+		}
+		route.Location = status.Path
+		routes[len(routes)-1] = route
+		routes = append(routes, status)
+		requests = append(requests, status.String())
+
+		if op != ops.Delete {
+			requests = append(requests, ops.Read.String())
+		}
+	}
+
+	importID, importedID, importAttrs := testImportID(ts)
+
+	// Attributes that only hold path parameters are not in the payload, so
+	// they are set on their own.
+	pathAttrs := make(map[string]string)
+
+	for name, prop := range ts.Properties {
+		if !prop.PathParam {
+			continue
+		}
+
+		pathAttrs[name] = importAttrs[name]
+		if pathAttrs[name] == "" {
+			pathAttrs[name] = "test-" + name
+		}
+	}
+
+	file := newGoFile(ts.Scope, ts.Package)
+
+	return r.renderGo(resourceTestTemplateName, file, map[string]interface{}{
+		"Schema":           ts,
+		"Payload":          string(payload),
+		"Routes":           routes,
+		"Requests":         requests,
+		"ImportID":         importID,
+		"ImportedID":       importedID,
+		"ImportAttributes": importAttrs,
+		"PathAttributes":   pathAttrs,
+	})
+}
+
+// testImportID returns the import ID unit tests import a resource by, the
+// resource ID it holds, and the values it sets on attributes.
+func testImportID(
+	ts *tf.TerraformSchema,
+) (string, string, map[string]string) {
+	attrs := ts.Resource.ImportAttributes()
+	values := make(map[string]string, len(attrs))
+	parts := make([]string, 0, len(attrs)+1)
+
+	for i, attr := range attrs {
+		value := fmt.Sprintf("test-%d", i+1)
+
+		switch ts.Properties[attr].Type {
+		case tf.TypeInt, tf.TypeFloat:
+			value = fmt.Sprint(i + 1)
+		case tf.TypeBool:
+			value = "true"
+		}
+
+		values[attr] = value
+		parts = append(parts, value)
+	}
+
+	parts = append(parts, "test-id")
+
+	return strings.Join(parts, "/"), "test-id", values
+}
+
+// testResponse returns the body an operation answers with in unit tests: its
+// response example, or else the example of the schema, or else a payload
+// that sets every attribute of the schema. Nothing is added to the body, so
+// the tests fail if the ID isn't where the resource reads it from.
+func testResponse(ts *tf.TerraformSchema, op *tf.Operation) map[string]interface{} {
+	if body, ok := op.Response.(map[string]interface{}); ok {
+		return body
+	}
+
+	if body, ok := ts.Example.(map[string]interface{}); ok {
+		return body
+	}
+
+	return schemaPayload(ts, true)
+}
+
+// testPayload returns an API payload that sets every attribute of a schema
+// users can set, to its example value or else a placeholder.
+func testPayload(ts *tf.TerraformSchema) map[string]interface{} {
+	return schemaPayload(ts, false)
+}
+
+// schemaPayload returns an API payload that sets the attributes of a schema
+// to their example value or else a placeholder, keyed by their names in the
+// API. Read-only attributes are only set if readOnly is true.
+func schemaPayload(
+	ts *tf.TerraformSchema,
+	readOnly bool,
+) map[string]interface{} {
+	example, _ := ts.Example.(map[string]interface{})
+	payload := make(map[string]interface{}, len(ts.Properties))
+
+	for _, name := range sortedKeys(ts.Properties) {
+		prop := ts.Properties[name]
+		if (isReadOnly(prop) && !readOnly) || prop.PathParam {
+			continue
+		}
+
+		if isNestedBlock(prop) {
+			nested := schemaPayload(prop.Elem.Schema, readOnly)
+			if prop.Object {
+				payload[prop.OriginalName] = nested
+			} else {
+				payload[prop.OriginalName] = []interface{}{nested}
+			}
+
+			continue
+		}
+
+		value := example[prop.OriginalName]
+		if value == nil {
+			value = prop.Example
+		}
+
+		if _, ok := exampleValue(prop, value); !ok || value == nil {
+			value = placeholderPayload(prop)
+		}
+
+		payload[prop.OriginalName] = value
+	}
+
+	return payload
+}
+
+// placeholderPayload returns the placeholder value of a property as decoded
+// JSON.
+func placeholderPayload(prop tf.TerraformProperty) interface{} {
+	primitive := func(t string) interface{} {
+		switch t {
+		case tf.TypeBool:
+			return false
+		case tf.TypeInt, tf.TypeFloat:
+			return 0.0
+		default:
+			return "example"
+		}
+	}
+
+	switch prop.Type {
+	case tf.TypeList, tf.TypeSet:
+		return []interface{}{primitive(elemType(prop))}
+	case tf.TypeMap:
+		return map[string]interface{}{"key": primitive(elemType(prop))}
+	default:
+		return placeholderJSON(prop)
+	}
+}
+
+// expandValue returns the Go expression that converts the attribute value v
+// of a property to its API representation.
+func (f *goFile) expandValue(prop tf.TerraformProperty) string {
+	switch {
+	case isNestedBlock(prop) && prop.Object:
+		return fmt.Sprintf("expandBlock(v, %s)", f.convertFunc("expand", prop))
+	case isNestedBlock(prop):
+		return fmt.Sprintf("expandBlocks(v, %s)", f.convertFunc("expand", prop))
+	case prop.Type == tf.TypeSet:
+		return "collectionItems(v)"
+	default:
+		return "v"
+	}
+}
+
+// flattenValue returns the Go expression that converts the API value v of a
+// property to its attribute value.
+func (f *goFile) flattenValue(prop tf.TerraformProperty) string {
+	switch {
+	case isNestedBlock(prop) && prop.Object:
+		return fmt.Sprintf("flattenBlock(v, %s)", f.convertFunc("flatten", prop))
+	case isNestedBlock(prop):
+		return fmt.Sprintf("flattenBlocks(v, %s)", f.convertFunc("flatten", prop))
+	default:
+		return "v"
+	}
+}
+
+// convertFunc returns how the file refers to the expand or flatten function,
+// by prefix, of the nested block of a property.
+func (f *goFile) convertFunc(prefix string, prop tf.TerraformProperty) string {
+	return f.ref(prop.Elem.Schema, prefix+prop.Elem.Schema.NameCamelCase)
+}
+
+// pathExpr returns the Go expression of the path of a request to an
+// operation, filling in its parameters from the resource data d.
+func pathExpr(op *tf.Operation) string {
+	params := op.PathParams()
+	if len(params) == 0 {
+		return internal.QuoteGoString(op.Path)
+	}
+
+	args := make([]string, 0, len(params))
+
+	for _, param := range params {
+		if attr := op.Params[param]; attr != "" {
+			args = append(args, fmt.Sprintf("pathParam(d.Get(%s))",
+				internal.QuoteGoString(attr)))
+		} else {
+			args = append(args, "pathParam(d.Id())")
+		}
+	}
+
+	return fmt.Sprintf("fmt.Sprintf(%s, %s)",
+		internal.QuoteGoString(op.PathFormat()), strings.Join(args, ", "))
+}
+
+// methodExpr returns the net/http constant of the operation's method.
+func methodExpr(op *tf.Operation) string {
+	switch op.Method {
+	case http.MethodPost:
+		return "http.MethodPost"
+	case http.MethodGet:
+		return "http.MethodGet"
+	case http.MethodPut:
+		return "http.MethodPut"
+	case http.MethodPatch:
+		return "http.MethodPatch"
+	case http.MethodDelete:
+		return "http.MethodDelete"
+	}
+
+	return internal.QuoteGoString(op.Method)
+}
@@ -0,0 +1,137 @@
+package openapi_test
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// TestRenderResource tests that the resource, conversions and unit tests of a
+// schema render to valid Go.
+func TestRenderResource(t *testing.T) {
+	s := &openapi3.Schema{
+		Type: "object",
+		Properties: openapi3.Schemas{
+			"name":   openapi3.NewStringSchema().NewRef(),
+			"tags":   openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).NewRef(),
+			"origin": openapi3.NewObjectSchema().WithProperty("host", openapi3.NewStringSchema()).NewRef(),
+		},
+	}
+
+	ts, diags := openapi.ConvertToTFSchema(
+		"Rule", tf.NewTerrformScope("Test"), s)
+	if diags.HasErrors() {
+		t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+	}
+
+	item := "/properties/{property_id}/rules/{id}"
+	ts.Resource = &tf.ResourceOperations{
+		Create: &tf.Operation{
+			Method: "POST",
+			Path:   "/rules",
+			Params: map[string]string{},
+		},
+		Read: &tf.Operation{
+			Method: "GET",
+			Path:   item,
+			Params: map[string]string{"property_id": "name", "id": ""},
+		},
+		Delete: &tf.Operation{
+			Method: "DELETE",
+			Path:   item,
+			Params: map[string]string{"property_id": "name", "id": ""},
+			Async:  true,
+		},
+		IDField: "id",
+	}
+
+	for name, render := range map[string]func(*tf.TerraformSchema) ([]byte, error){
+		"RenderResource":     openapi.RenderResource,
+		"RenderExpand":       openapi.RenderExpand,
+		"RenderResourceTest": openapi.RenderResourceTest,
+	} {
+		code, err := render(ts)
+		if err != nil {
+			t.Errorf("%s() error = %v\n%s", name, err, code)
+			continue
+		}
+
+		if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+			t.Errorf("%s() code does not parse: %v\n%s", name, err, code)
+		}
+	}
+}
+
+// TestRenderResourceTest_ResponseBodies tests that the stand-in for the API
+// answers with the properties of the schema only, by their API names.
+func TestRenderResourceTest_ResponseBodies(t *testing.T) {
+	s := &openapi3.Schema{
+		Type: "object",
+		Properties: openapi3.Schemas{
+			"ruleId":   &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string", ReadOnly: true}},
+			"hostName": openapi3.NewStringSchema().NewRef(),
+			"port":     openapi3.NewIntegerSchema().WithMin(1).NewRef(),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		idField string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "ID property",
+			idField: "ruleId",
+			want: []string{
+				`\"ruleId\":\"example\"`, `\"hostName\":\"example\"`, `\"port\":1`,
+			},
+			notWant: []string{`\"rule_id\"`, `\"host_name\"`},
+		},
+		{
+			name:    "missing ID property",
+			idField: "id",
+			notWant: []string{`\"id\"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, diags := openapi.ConvertToTFSchema(
+				"Rule", tf.NewTerrformScope("Test"), s)
+			if diags.HasErrors() {
+				t.Fatalf("ConvertToTFSchema() diags = %v", diags)
+			}
+
+			params := map[string]string{"rule_id": ""}
+			ts.Resource = &tf.ResourceOperations{
+				Create:  &tf.Operation{Method: "POST", Path: "/rules", Params: map[string]string{}},
+				Read:    &tf.Operation{Method: "GET", Path: "/rules/{rule_id}", Params: params},
+				Delete:  &tf.Operation{Method: "DELETE", Path: "/rules/{rule_id}", Params: params},
+				IDField: tt.idField,
+			}
+
+			code, err := openapi.RenderResourceTest(ts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(string(code), want) {
+					t.Errorf("unit test = %s, want it to contain %s", code, want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(code), notWant) {
+					t.Errorf("unit test = %s, want it not to contain %s", code, notWant)
+				}
+			}
+		})
+	}
+}
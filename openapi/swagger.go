@@ -0,0 +1,263 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+	"github.com/stevenpaz/tf-schema-gen/internal"
+)
+
+// swaggerVersion is the only Swagger version that is upconverted.
+const swaggerVersion = "2.0"
+
+// swaggerSources map the pointers of OpenAPI 3 components back to where
+// Swagger 2.0 declares them.
+var swaggerSources = map[string]string{
+	"#/components/schemas":         "#/definitions",
+	"#/components/parameters":      "#/parameters",
+	"#/components/responses":       "#/responses",
+	"#/components/securitySchemes": "#/securityDefinitions",
+}
+
+// externalRefKey holds the external references of a Swagger 2.0 document while
+// it is upconverted, because openapi2conv resolves the upconverted document
+// without loading other files.
+const externalRefKey = "x-tf-schema-gen-ref"
+
+// loadSwagger upconverts a Swagger 2.0 document to OpenAPI 3. External
+// references are resolved by loader, relative to location, once the document is
+// upconverted, so the files they point to are read as OpenAPI 3.
+func loadSwagger(
+	data []byte,
+	loader *openapi3.Loader,
+	location *url.URL,
+) (*openapi3.T, internal.Diagnostics) {
+	var (
+		diags internal.Diagnostics
+		raw   interface{}
+	)
+
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		diags.AddError("", err.Error())
+		return nil, diags
+	}
+
+	// Swagger 2.0 discriminators are property names, which OpenAPI 3 wraps
+	// in an object.
+	upgradeDiscriminators(raw)
+	external := hideExternalRefs(raw)
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		diags.AddError("", err.Error())
+		return nil, diags
+	}
+
+	var doc2 openapi2.T
+	if err := json.Unmarshal(data, &doc2); err != nil {
+		diags.AddError("", err.Error())
+		return nil, diags
+	}
+
+	diags.Append(checkSwagger(&doc2))
+
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		diags.AddError("", fmt.Sprintf(
+			"error converting Swagger 2.0 to OpenAPI 3: %s", err))
+
+		return nil, diags
+	}
+
+	diags.Append(copyExamples(&doc2, doc))
+
+	if !external {
+		return doc, diags
+	}
+
+	// Put the external references back and resolve them with loader.
+	data, err = json.Marshal(doc)
+	if err == nil {
+		raw = nil
+		err = json.Unmarshal(data, &raw)
+	}
+
+	if err == nil {
+		restoreExternalRefs(raw)
+		data, err = json.Marshal(raw)
+	}
+
+	if err == nil {
+		doc, err = loader.LoadFromDataWithPath(data, location)
+	}
+
+	if err != nil {
+		diags.AddError("", err.Error())
+		return nil, diags
+	}
+
+	return doc, diags
+}
+
+// hideExternalRefs moves the references to other files in a decoded document
+// to externalRefKey, and returns true if there were any.
+func hideExternalRefs(node interface{}) bool {
+	found := false
+
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+			delete(v, "$ref")
+			v[externalRefKey] = ref
+			found = true
+		}
+
+		for _, child := range v {
+			found = hideExternalRefs(child) || found
+		}
+	case []interface{}:
+		for _, child := range v {
+			found = hideExternalRefs(child) || found
+		}
+	}
+
+	return found
+}
+
+// restoreExternalRefs replaces every object of a decoded document that was
+// upconverted from a hidden external reference with the reference.
+func restoreExternalRefs(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v[externalRefKey].(string); ok {
+			for key := range v {
+				delete(v, key)
+			}
+
+			v["$ref"] = ref
+
+			return
+		}
+
+		for _, child := range v {
+			restoreExternalRefs(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			restoreExternalRefs(child)
+		}
+	}
+}
+
+// upgradeDiscriminators replaces every string discriminator in a decoded
+// document with its OpenAPI 3 object form.
+func upgradeDiscriminators(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if name, ok := child.(string); ok && key == "discriminator" {
+				v[key] = map[string]interface{}{"propertyName": name}
+				continue
+			}
+
+			upgradeDiscriminators(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			upgradeDiscriminators(child)
+		}
+	}
+}
+
+// checkSwagger reports the constructs of a Swagger 2.0 document that are
+// dropped when it is upconverted.
+func checkSwagger(doc *openapi2.T) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	check := func(pointer string, p *openapi2.Parameter) {
+		if p == nil || p.CollectionFormat == "" || p.CollectionFormat == "csv" {
+			return
+		}
+
+		diags.AddWarning(internal.ChildJSONPointer(pointer, "collectionFormat"),
+			fmt.Sprintf("collectionFormat '%s' of parameter '%s' has no "+
+				"OpenAPI 3 mapping and is ignored", p.CollectionFormat, p.Name))
+	}
+
+	for _, name := range sortedKeys(doc.Parameters) {
+		check(internal.JSONPointer("parameters", name), doc.Parameters[name])
+	}
+
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		for i, p := range item.Parameters {
+			check(internal.JSONPointer("paths", path, "parameters",
+				fmt.Sprint(i)), p)
+		}
+
+		ops := item.Operations()
+		for _, method := range sortedKeys(ops) {
+			for i, p := range ops[method].Parameters {
+				check(internal.JSONPointer("paths", path,
+					strings.ToLower(method), "parameters", fmt.Sprint(i)), p)
+			}
+		}
+	}
+
+	return diags
+}
+
+// copyExamples copies the response examples of a Swagger 2.0 document, which
+// openapi2conv drops, to the media types of the upconverted document.
+func copyExamples(doc2 *openapi2.T, doc3 *openapi3.T) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	copyResponse := func(pointer string, from *openapi2.Response, to *openapi3.ResponseRef) {
+		if from == nil || to == nil || to.Value == nil {
+			return
+		}
+
+		for _, mime := range sortedKeys(from.Examples) {
+			mt := to.Value.Content.Get(mime)
+			if mt == nil {
+				diags.AddWarning(
+					internal.ChildJSONPointer(pointer, "examples", mime),
+					fmt.Sprintf("example for '%s' is not among the produced "+
+						"media types and is ignored", mime))
+
+				continue
+			}
+
+			mt.Example = from.Examples[mime]
+		}
+	}
+
+	for _, name := range sortedKeys(doc2.Responses) {
+		copyResponse(internal.JSONPointer("responses", name),
+			doc2.Responses[name], doc3.Components.Responses[name])
+	}
+
+	for _, path := range sortedKeys(doc2.Paths) {
+		ops := doc2.Paths[path].Operations()
+		for _, method := range sortedKeys(ops) {
+			op3 := doc3.Paths[path].GetOperation(method)
+			if op3 == nil {
+				continue
+			}
+
+			for _, status := range sortedKeys(ops[method].Responses) {
+				copyResponse(internal.JSONPointer("paths", path,
+					strings.ToLower(method), "responses", status),
+					ops[method].Responses[status], op3.Responses[status])
+			}
+		}
+	}
+
+	return diags
+}
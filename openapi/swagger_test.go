@@ -0,0 +1,202 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const swaggerSpec = `swagger: "2.0"
+info:
+  title: Test
+  version: "1.0"
+host: api.example.com
+basePath: /v1
+schemes: [https]
+consumes: [application/json]
+produces: [application/json]
+securityDefinitions:
+  key:
+    type: apiKey
+    in: header
+    name: X-Api-Key
+paths:
+  /rules:
+    get:
+      parameters:
+        - name: tags
+          in: query
+          type: array
+          items:
+            type: string
+          collectionFormat: pipes
+      responses:
+        "200":
+          description: ok
+    post:
+      parameters:
+        - name: body
+          in: body
+          schema:
+            $ref: '#/definitions/Rule'
+      responses:
+        "201":
+          description: created
+          schema:
+            $ref: '#/definitions/Rule'
+          examples:
+            application/json:
+              id: r-1
+  /rules/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        type: string
+    get:
+      responses:
+        "200":
+          description: ok
+          schema:
+            $ref: '#/definitions/Rule'
+    delete:
+      responses:
+        "204":
+          description: deleted
+definitions:
+  Rule:
+    type: object
+    discriminator: kind
+    required: [kind]
+    properties:
+      id:
+        type: string
+        readOnly: true
+      kind:
+        type: string
+  Empty:
+    type: object
+`
+
+func TestOpenAPI3ToTerraform_Swagger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(swaggerSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform() diags = %v", diags)
+	}
+
+	if len(scope.Schemas) != 1 || scope.Schemas[0].Name != "Rule" {
+		t.Fatalf("Schemas = %+v, want Rule", scope.Schemas)
+	}
+
+	ops := scope.Schemas[0].Resource
+	if ops == nil {
+		t.Fatal("Rule is not a resource")
+	}
+
+	wantResponse := map[string]interface{}{"id": "r-1"}
+	if !reflect.DeepEqual(ops.Create.Response, wantResponse) {
+		t.Errorf("Create.Response = %v, want %v", ops.Create.Response, wantResponse)
+	}
+
+	if got := scope.Provider.BaseURL.Default; got != "https://api.example.com/v1" {
+		t.Errorf("BaseURL.Default = %q, want %q", got, "https://api.example.com/v1")
+	}
+
+	pointers := make(map[string]int)
+	for _, d := range diags {
+		pointers[d.Pointer] = d.Line
+	}
+
+	for pointer, line := range map[string]int{
+		"#/paths/~1rules/get/parameters/0/collectionFormat": 24,
+		"#/definitions/Empty":                               69,
+	} {
+		if got, ok := pointers[pointer]; !ok || got != line {
+			t.Errorf("diagnostic at %s on line %d, want line %d (diags = %v)",
+				pointer, got, line, diags)
+		}
+	}
+}
+
+func TestOpenAPI3ToTerraform_SwaggerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte("swagger: \"1.2\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if !diags.HasErrors() {
+		t.Errorf("OpenAPI3ToTerraform() diags = %v, want an error", diags)
+	}
+}
+
+const swaggerExternalSpec = `swagger: "2.0"
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /rules:
+    post:
+      parameters:
+        - name: body
+          in: body
+          schema:
+            $ref: '#/definitions/Rule'
+      responses:
+        "201":
+          description: created
+          schema:
+            $ref: '#/definitions/Rule'
+definitions:
+  Rule:
+    type: object
+    properties:
+      id:
+        type: string
+        readOnly: true
+      origin:
+        $ref: '../schemas/origin.yaml#/Origin'
+`
+
+func TestOpenAPI3ToTerraform_SwaggerRefRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/spec.yaml": swaggerExternalSpec,
+		"schemas/origin.yaml": `Origin:
+  type: object
+  properties:
+    host:
+      type: string
+`,
+	})
+
+	path := filepath.Join(dir, "api", "spec.yaml")
+
+	_, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{})
+	if !diags.HasErrors() || !strings.Contains(diags.Error(), "escapes the root") {
+		t.Errorf("OpenAPI3ToTerraform() diags = %v, want a ref escaping the root", diags)
+	}
+
+	scope, diags := openapi.OpenAPI3ToTerraform(path, openapi.Options{RefRoot: dir})
+	if diags.HasErrors() {
+		t.Fatalf("OpenAPI3ToTerraform(RefRoot) diags = %v", diags)
+	}
+
+	var names []string
+	for _, ts := range scope.Schemas {
+		names = append(names, ts.Name)
+	}
+
+	if got, want := strings.Join(names, ","), "Origin,Rule"; got != want {
+		t.Errorf("schemas = %s, want %s", got, want)
+	}
+}
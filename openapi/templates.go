@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// builtinTemplateFiles are the templates the generated files are rendered
+// with, unless they are overridden.
+//
+//go:embed templates/*.tmpl
+var builtinTemplateFiles embed.FS
+
+// The names of the templates, which are the names of their files.
+const (
+	schemaTemplateName              = "schema.go.tmpl"
+	resourceTemplateName            = "resource.go.tmpl"
+	expandTemplateName              = "expand.go.tmpl"
+	clientTemplateName              = "client.go.tmpl"
+	clientAliasTemplateName         = "client_alias.go.tmpl"
+	resourceHelpersTemplateName     = "resource_helpers.go.tmpl"
+	asyncTemplateName               = "async.go.tmpl"
+	resourceTestTemplateName        = "resource_unit_test.go.tmpl"
+	resourceTestHelpersTemplateName = "resource_helpers_test.go.tmpl"
+	providerTemplateName            = "provider.go.tmpl"
+	accTestTemplateName             = "acctest.go.tmpl"
+	accTestProviderTemplateName     = "acctest_provider.go.tmpl"
+	docsTemplateName                = "docs.md.tmpl"
+)
+
+// builtinTemplates is the set of the built-in templates.
+var builtinTemplates = template.Must(
+	newTemplateSet().ParseFS(builtinTemplateFiles, "templates/*.tmpl"))
+
+// newTemplateSet returns an empty set of templates, with the functions
+// templates may use.
+func newTemplateSet() *template.Template {
+	return template.New("").Funcs(templateFuncs).Funcs(fileFuncs).
+		Funcs(resourceFuncs).Funcs(propertyFuncs)
+}
+
+// LoadTemplates returns the built-in templates, overridden by the templates
+// in dir. Every *.tmpl file in dir replaces the built-in template with the
+// same name, and the templates it defines replace those with the same name,
+// so a file may override a single sub-template like "properties" too.
+func LoadTemplates(dir string) (*template.Template, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing templates: %w", err)
+	}
+
+	if len(paths) == 0 {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("error reading templates: %w", err)
+		}
+	}
+
+	templates, err := builtinTemplates.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("error cloning templates: %w", err)
+	}
+
+	for _, path := range paths {
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template: %w", err)
+		}
+
+		_, err = templates.New(filepath.Base(path)).Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template: %w", err)
+		}
+	}
+
+	return templates, nil
+}
+
+// renderer renders the generated files with a set of templates.
+type renderer struct {
+	templates *template.Template
+}
+
+// builtinRenderer renders the generated files with the built-in templates.
+var builtinRenderer = &renderer{templates: builtinTemplates}
+
+// newRenderer returns a renderer with the built-in templates, overridden by
+// those in dir if it is not empty.
+func newRenderer(dir string) (*renderer, error) {
+	if dir == "" {
+		return builtinRenderer, nil
+	}
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &renderer{templates: templates}, nil
+}
+
+// renderGo renders a Go file with the template called name. If the code
+// cannot be formatted, the unformatted code is returned along with the
+// error.
+func (r *renderer) renderGo(
+	name string,
+	file *goFile,
+	data interface{},
+) ([]byte, error) {
+	return renderGo(r.templates.Lookup(name), file, data)
+}
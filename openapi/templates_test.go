@@ -0,0 +1,197 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const templatesSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    OriginGroup:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestGenerateFiles_Templates(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates map[string]string
+		want      string
+		wantErr   string
+	}{
+		{
+			name: "file",
+			templates: map[string]string{
+				"schema.go.tmpl": `package {{packageName}}
+
+// {{snake .Name}} {{camel .Name}} {{quote .Name}}
+{{indent 2 "var _ = 1"}}
+`,
+			},
+			want: "// origin_group OriginGroup \"OriginGroup\"",
+		},
+		{
+			name: "sub-template",
+			templates: map[string]string{
+				"properties.tmpl": `{{define "properties" -}}
+map[string]*schema.Schema{ /* custom */ }
+{{- end}}`,
+			},
+			want: "map[string]*schema.Schema{ /* custom */ }",
+		},
+		{
+			name: "parse error",
+			templates: map[string]string{
+				"schema.go.tmpl": "{{if}}",
+			},
+			wantErr: "schema.go.tmpl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for name, text := range tt.templates {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			files, diags, err := openapi.GenerateFiles(
+				strings.NewReader(templatesSpec), "spec.yaml", "out",
+				openapi.Options{TemplatesDir: dir})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GenerateFiles() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+
+			var schema []byte
+
+			for _, f := range files {
+				if filepath.Base(f.Path) == "origin_group_schema.go" {
+					schema = f.Data
+				}
+			}
+
+			if !strings.Contains(string(schema), tt.want) {
+				t.Errorf("schema file = %s, want it to contain %q", schema, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTemplates_MissingDir(t *testing.T) {
+	_, err := openapi.LoadTemplates(filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Error("LoadTemplates() error = nil, want an error")
+	}
+}
+
+const templateFuncsSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Origin:
+      type: object
+      properties:
+        hostName:
+          type: string
+          pattern: '^[a-z.]+$'
+        port:
+          type: integer
+          minimum: 1
+          default: 443
+        tags:
+          type: array
+          uniqueItems: true
+          items:
+            type: string
+        backends:
+          type: array
+          items:
+            type: object
+            properties:
+              weight:
+                type: number
+`
+
+func TestGenerateFiles_TemplateFuncs(t *testing.T) {
+	dir := t.TempDir()
+
+	tmpl := `package {{packageName}}
+{{range $key, $value := .Properties}}
+// {{$key}}: {{originalName $key $value}} {{goType $value}} {{tfsdkType $value}} ` +
+		`elem={{elemType $value}} nested={{isNested $value}} ` +
+		`default={{hasDefault $value}} ` +
+		`validators={{range validators $value}}{{.SDKv2}}{{end}}
+{{- end}}
+`
+
+	err := os.WriteFile(filepath.Join(dir, "schema.go.tmpl"), []byte(tmpl), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, diags, err := openapi.GenerateFiles(
+		strings.NewReader(templateFuncsSpec), "spec.yaml", "out",
+		openapi.Options{TemplatesDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	var schema string
+
+	for _, f := range files {
+		if filepath.Base(f.Path) == "origin_schema.go" {
+			schema = string(f.Data)
+		}
+	}
+
+	for _, want := range []string{
+		"// backends: backends []interface{} types.List elem= nested=true " +
+			"default=false validators=\n",
+		"// host_name: hostName string types.String elem= nested=false " +
+			"default=false validators=[validation.StringMatch(" +
+			"regexp.MustCompile(`^[a-z.]+$`), \"\")]",
+		"// port: port int types.Int64 elem= nested=false " +
+			"default=true validators=[validation.IntAtLeast(1)]",
+		"// tags: tags *schema.Set types.Set elem=TypeString nested=false " +
+			"default=false validators=\n",
+	} {
+		if !strings.Contains(schema, want) {
+			t.Errorf("schema file = %s, want it to contain %q", schema, want)
+		}
+	}
+}
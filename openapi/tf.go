@@ -2,6 +2,11 @@ package openapi
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -9,162 +14,646 @@ import (
 	"github.com/stevenpaz/tf-schema-gen/tf"
 )
 
-func OpenAPI3ToTerraform(filePath string) (*tf.TerraformScope, error) {
-	doc, err := openapi3.NewLoader().LoadFromFile(filePath)
+// OpenAPI3ToTerraform converts every schema in the OpenAPI 3.0 document at
+// filePath. Swagger 2.0 documents are upconverted first. Problems are collected
+// across the whole document rather than stopping at the first one, and are
+// located in the source file.
+func OpenAPI3ToTerraform(
+	filePath string,
+	opts Options,
+) (*tf.TerraformScope, internal.Diagnostics) {
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, err
+		return nil, internal.Diagnostics{{
+			Severity: internal.SeverityError,
+			Message:  err.Error(),
+			File:     filePath,
+		}}
+	}
+
+	return convertDocument(data, filePath, opts)
+}
+
+// OpenAPI3ToTerraformFromReader is like OpenAPI3ToTerraform, but reads the
+// document from r. name is the file diagnostics are reported in, and external
+// references are resolved relative to.
+func OpenAPI3ToTerraformFromReader(
+	r io.Reader,
+	name string,
+	opts Options,
+) (*tf.TerraformScope, internal.Diagnostics) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, internal.Diagnostics{{
+			Severity: internal.SeverityError,
+			Message:  err.Error(),
+			File:     name,
+		}}
+	}
+
+	return convertDocument(data, name, opts)
+}
+
+// convertDocument converts the document in data, read from filePath.
+func convertDocument(
+	data []byte,
+	filePath string,
+	opts Options,
+) (*tf.TerraformScope, internal.Diagnostics) {
+	// Diagnostics are still useful without positions, so a source map that
+	// fails to load is not an error in itself.
+	sm, _ := internal.NewSourceMap(filePath, data)
+
+	doc, diags := loadDocument(data, filePath, opts.RefRoot)
+	if doc == nil {
+		diags.Locate(sm)
+		return nil, diags
+	}
+
+	filter, err := newSchemaFilter(doc.T, opts)
+	if err != nil {
+		diags = append(diags, internal.Diagnostic{
+			Severity: internal.SeverityError,
+			Message:  err.Error(),
+			File:     filePath,
+		})
+
+		return nil, diags
 	}
 
 	scope := tf.NewTerrformScope(doc.Info.Title)
 
-	for name, schema := range doc.Components.Schemas {
-		if ts, err := ConvertToTFSchema(name, scope, schema.Value); err == nil {
-			scope.AddSchema(ts)
-		} else {
-			return nil,
-				fmt.Errorf("failed to convert schema '%s': %w", name, err)
+	if opts.PackageName != "" {
+		if !internal.IsPackageName(opts.PackageName) {
+			diags = append(diags, internal.Diagnostic{
+				Severity: internal.SeverityError,
+				Message: fmt.Sprintf("package name '%s' is not a valid Go "+
+					"package name", opts.PackageName),
+				File: filePath,
+			})
+
+			return nil, diags
+		}
+
+		scope.GoPackage = opts.PackageName
+	}
+
+	c := newConverter(scope, opts)
+
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		if !filter.selects(name) {
+			continue
+		}
+
+		s := doc.Components.Schemas[name].Value
+
+		if s != nil && len(s.Properties) == 0 {
+			diags.AddWarning(
+				internal.JSONPointer("components", "schemas", name),
+				fmt.Sprintf("schema '%s' has no properties and is skipped",
+					name))
+
+			continue
+		}
+
+		_, schemaDiags := c.component(name, s)
+		diags.Append(schemaDiags)
+	}
+
+	diags.Append(findResources(doc.T, c.components))
+
+	provider, providerDiags := convertProvider(doc.T)
+	scope.Provider = provider
+	diags.Append(providerDiags)
+
+	// Components are converted on demand when referenced, so add them to the
+	// scope afterwards to keep the output in a stable order.
+	for _, name := range sortedKeys(c.components) {
+		ts := c.components[name]
+		if ts == nil {
+			continue
+		}
+
+		if filter.excludes(name) {
+			diags.AddWarning(
+				internal.JSONPointer("components", "schemas", name),
+				fmt.Sprintf("schema '%s' is excluded, but generated because "+
+					"other schemas reference it", name))
 		}
+
+		scope.AddSchema(ts)
 	}
 
-	return scope, nil
+	diags.Append(checkIdentifiers(scope))
+	diags.Append(splitPackages(scope, doc.T, c.components, opts))
+
+	for i := range diags {
+		diags[i].Pointer = doc.sourcePointer(diags[i].Pointer)
+	}
+
+	diags.Locate(sm)
+
+	return scope, diags
 }
 
-// ConvertToTFSchema converts an OpenAPI schema to a Terraform schema.
+// ConvertToTFSchema converts an OpenAPI component schema to a Terraform schema
+// with the default Options and validates the result.
 func ConvertToTFSchema(
 	name string,
 	scope *tf.TerraformScope,
 	s *openapi3.Schema,
-) (*tf.TerraformSchema, error) {
+) (*tf.TerraformSchema, internal.Diagnostics) {
+	return newConverter(scope, Options{}).component(name, s)
+}
+
+// converter converts OpenAPI schemas into Terraform schemas. Component schemas
+// referenced by a property are converted on demand, once, so nested blocks can
+// share them.
+type converter struct {
+	scope *tf.TerraformScope
+	// components holds every component converted so far. Components that
+	// failed to convert are recorded as nil.
+	components map[string]*tf.TerraformSchema
+	// converting holds the components currently being converted, to detect
+	// reference cycles.
+	converting map[string]bool
+	// renameStrategy handles reserved and colliding attribute names.
+	renameStrategy RenameStrategy
+}
+
+func newConverter(scope *tf.TerraformScope, opts Options) *converter {
+	return &converter{
+		scope:          scope,
+		components:     make(map[string]*tf.TerraformSchema),
+		converting:     make(map[string]bool),
+		renameStrategy: opts.RenameStrategy,
+	}
+}
+
+// component converts and validates the named component schema.
+func (c *converter) component(
+	name string,
+	s *openapi3.Schema,
+) (*tf.TerraformSchema, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	if ts, ok := c.components[name]; ok {
+		return ts, nil
+	}
+
+	pointer := internal.JSONPointer("components", "schemas", name)
+
+	if c.converting[name] {
+		diags.AddError(pointer, fmt.Sprintf(
+			"schema '%s' references itself, which Terraform cannot represent",
+			name))
+
+		return nil, diags
+	}
+
 	if s == nil {
-		return nil, fmt.Errorf("schema is nil")
+		diags.AddError(pointer, fmt.Sprintf("schema '%s' is nil", name))
+		return nil, diags
 	}
 
-	tfSchema := tf.NewTerrformSchema(name, scope)
+	c.converting[name] = true
+	ts, diags := c.convertSchema(name, pointer, s, false)
+	delete(c.converting, name)
 
-	for name, prop := range s.Properties {
-		propSchema := prop.Value
+	if !diags.HasErrors() {
+		for _, e := range ts.Validate() {
+			diags.AddError(e.Pointer, e.Error())
+		}
+	}
 
-		tfProp := tf.NewTerraformProperty()
+	if diags.HasErrors() {
+		c.components[name] = nil
+	} else {
+		c.components[name] = ts
+	}
 
-		if vf := BuildValidationFunc(propSchema); len(vf) > 0 {
-			tfProp.SetValidateFunc(vf)
-			tfSchema.HasValidateFuncs = true
+	return ts, diags
+}
+
+// convertSchema converts the properties of an OpenAPI object schema. inline is
+// true for blocks declared inline in another schema.
+func (c *converter) convertSchema(
+	name string,
+	pointer string,
+	s *openapi3.Schema,
+	inline bool,
+) (*tf.TerraformSchema, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	tfSchema := tf.NewTerrformSchema(name, c.scope)
+	tfSchema.Pointer = pointer
+	tfSchema.Description = s.Description
+	tfSchema.Example = schemaExample(s)
+
+	propNames := sortedKeys(s.Properties)
+
+	attrNames, nameDiags := c.attributeNames(name, pointer, s, inline)
+	diags.Append(nameDiags)
+
+	for _, propName := range propNames {
+		attrName, ok := attrNames[propName]
+		if !ok {
+			continue
 		}
 
-		tfProp.SetDescription(propSchema.Description)
+		propPointer := internal.ChildJSONPointer(
+			pointer, "properties", propName)
+
+		tfProp, propDiags := c.convertProperty(
+			name+internal.ToCamelCase(propName),
+			propPointer,
+			s.Properties[propName],
+			isRequired(s, propName))
+		diags.Append(propDiags)
 
-		if t, err := GetTFType(propSchema); err == nil {
-			tfProp.Type = t
-		} else {
-			return nil, err
+		if tfProp == nil {
+			continue
 		}
 
-		if propSchema.Nullable || propSchema.AllowEmptyValue {
-			tfProp.SetOptional(true)
+		tfProp.OriginalName = propName
+
+		if tfProp.ValidateFunc != nil || hasInlineValidateFuncs(tfProp) {
+			tfSchema.HasValidateFuncs = true
 		}
 
-		if propSchema.ReadOnly {
-			tfProp.SetComputed(true)
+		tfSchema.AddProp(attrName, tfProp)
+	}
+
+	return tfSchema, diags
+}
+
+// convertProperty converts a single OpenAPI property. nestedName is the name
+// given to a nested block declared inline in the property.
+func (c *converter) convertProperty(
+	nestedName string,
+	pointer string,
+	ref *openapi3.SchemaRef,
+	required bool,
+) (*tf.TerraformProperty, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	if ref == nil || ref.Value == nil {
+		diags.AddError(pointer, "property has no schema")
+		return nil, diags
+	}
+
+	propSchema := ref.Value
+
+	tfProp := tf.NewTerraformProperty()
+	tfProp.Pointer = pointer
+
+	tfProp.SetDescription(propSchema.Description)
+	tfProp.Example = schemaExample(propSchema)
+
+	if t, err := GetTFType(propSchema); err == nil {
+		tfProp.Type = t
+	} else {
+		diags.AddError(pointer, err.Error())
+		return nil, diags
+	}
+
+	switch tfProp.Type {
+	case tf.TypeList, tf.TypeSet:
+		diags.Append(c.convertItems(
+			tfProp, nestedName, internal.ChildJSONPointer(pointer, "items"),
+			propSchema.Items))
+
+		if propSchema.MaxItems != nil {
+			tfProp.SetMaxItems(int(*propSchema.MaxItems))
 		}
 
-		// Check if the property is required.
-		for _, required := range s.Required {
-			if required == name {
-				tfProp.SetRequired(true)
-				break
+		if propSchema.MinItems > 0 {
+			tfProp.SetMinItems(int(propSchema.MinItems))
+		}
+	case tf.TypeMap:
+		if len(propSchema.Properties) > 0 {
+			// Objects with known properties become a single nested block.
+			nested, nestedDiags := c.nested(nestedName, pointer, ref)
+			diags.Append(nestedDiags)
+
+			tfProp.Type = tf.TypeList
+			tfProp.SetElemSchema(nested)
+			tfProp.SetMaxItems(1)
+			tfProp.Object = true
+		} else if ap := propSchema.AdditionalProperties.Schema; ap != nil &&
+			ap.Value != nil {
+			t, err := GetTFType(ap.Value)
+			if err != nil || t == tf.TypeList || t == tf.TypeMap {
+				diags.AddError(
+					internal.ChildJSONPointer(pointer, "additionalProperties"),
+					"maps may only contain primitive values")
+			} else {
+				tfProp.SetElemType(t)
 			}
 		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Only validate values the user can set.
+	if !propSchema.ReadOnly && !tfProp.IsCollection() {
+		tfProp.Validators = BuildValidators(propSchema)
+		tfProp.SetValidateFunc(tf.SDKv2ValidateFunc(tfProp.Validators))
 
-		// If not marked as Read-Only or Required, mark as optional.
-		if !tfProp.IsComputed() && !tfProp.IsRequired() {
-			tfProp.SetOptional(true)
+		if buildEnumValidator(propSchema) != nil {
+			tfProp.Enum = enumValues(propSchema)
 		}
 
-		// validate tfProp before adding and throw error if invalid
-		if errs := tfProp.Validate(); len(errs) > 0 {
-			return nil, fmt.Errorf(strings.Join(errs, "\n"))
+		if propSchema.Pattern != "" && propSchema.Type == TypeString &&
+			!isGoRegexp(propSchema.Pattern) {
+			diags.AddWarning(internal.ChildJSONPointer(pointer, "pattern"),
+				"pattern is not a regular expression Go supports, so values "+
+					"are not checked against it")
 		}
+	}
 
-		tfSchema.AddProp(internal.ToSnakeCase(name), tfProp)
+	if propSchema.Nullable || propSchema.AllowEmptyValue {
+		tfProp.SetOptional(true)
 	}
 
-	return tfSchema, nil
+	if propSchema.ReadOnly {
+		tfProp.SetComputed(true)
+	}
+
+	if required {
+		tfProp.SetRequired(true)
+	}
+
+	// If not marked as Read-Only or Required, mark as optional.
+	if !tfProp.IsComputed() && !tfProp.IsRequired() {
+		tfProp.SetOptional(true)
+	}
+
+	if propSchema.Default != nil {
+		switch {
+		case tfProp.IsRequired() || tfProp.IsComputed():
+			diags.AddWarning(pointer,
+				"default is ignored for required and read-only properties")
+		case tfProp.IsCollection() || tfProp.Type == tf.TypeMap:
+			diags.AddWarning(pointer,
+				"default is ignored for lists, sets and maps")
+		default:
+			tfProp.SetDefault(propSchema.Default)
+		}
+	}
+
+	return tfProp, diags
+}
+
+// convertItems sets the Elem of a list or set property from its items schema.
+func (c *converter) convertItems(
+	tfProp *tf.TerraformProperty,
+	nestedName string,
+	pointer string,
+	items *openapi3.SchemaRef,
+) internal.Diagnostics {
+	var diags internal.Diagnostics
+
+	if items == nil || items.Value == nil {
+		diags.AddError(pointer, "arrays must declare their items")
+		return diags
+	}
+
+	t, err := GetTFType(items.Value)
+	if err != nil {
+		diags.AddError(pointer, err.Error())
+		return diags
+	}
+
+	switch t {
+	case tf.TypeList, tf.TypeSet:
+		diags.AddError(pointer, "arrays of arrays are not supported")
+	case tf.TypeMap:
+		if len(items.Value.Properties) == 0 {
+			diags.AddError(pointer, "array items must declare properties")
+			return diags
+		}
+
+		nested, nestedDiags := c.nested(nestedName, pointer, items)
+		diags.Append(nestedDiags)
+		tfProp.SetElemSchema(nested)
+	default:
+		tfProp.SetElemType(t)
+	}
+
+	return diags
+}
+
+// nested converts the object schema of a nested block. Schemas referenced from
+// components are shared; inline schemas are converted in place.
+func (c *converter) nested(
+	name string,
+	pointer string,
+	ref *openapi3.SchemaRef,
+) (*tf.TerraformSchema, internal.Diagnostics) {
+	var diags internal.Diagnostics
+
+	if component := componentName(ref.Ref); component != "" {
+		ts, componentDiags := c.component(component, ref.Value)
+		diags.Append(componentDiags)
+
+		if ts == nil || componentDiags.HasErrors() {
+			diags.AddError(pointer, fmt.Sprintf(
+				"referenced schema '%s' could not be converted", component))
+		}
+
+		return ts, diags
+	}
+
+	ts, diags := c.convertSchema(name, pointer, ref.Value, true)
+	ts.Inline = true
+
+	return ts, diags
+}
+
+// componentName returns the name of the component schema a $ref points to,
+// or an empty string for inline schemas.
+func componentName(ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	// References to whole files are named after the file.
+	i := strings.Index(ref, "#")
+	if i < 0 {
+		name := path.Base(ref)
+		return internal.ToCamelCase(strings.TrimSuffix(name, path.Ext(name)))
+	}
+
+	tokens := internal.SplitJSONPointer(ref[i+1:])
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	return tokens[len(tokens)-1]
+}
+
+// isRequired returns true if the named property is required by s.
+func isRequired(s *openapi3.Schema, name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasInlineValidateFuncs returns true if a nested block declared inline in the
+// property uses validation functions.
+func hasInlineValidateFuncs(tfProp *tf.TerraformProperty) bool {
+	return tfProp.Elem != nil && tfProp.Elem.Schema != nil &&
+		tfProp.Elem.Schema.Inline && tfProp.Elem.Schema.HasValidateFuncs
 }
 
 // BuildValidationFunc builds a Terraform validation from an OpenAPI schema.
 func BuildValidationFunc(s *openapi3.Schema) string {
+	return tf.SDKv2ValidateFunc(BuildValidators(s))
+}
+
+// BuildValidators builds the rules the values of an OpenAPI schema must
+// follow, in the order their SDKv2 validation functions are combined.
+func BuildValidators(s *openapi3.Schema) []tf.Validator {
 	if s == nil {
-		return ""
+		return nil
 	}
 
-	t := s.Type
+	var validators []tf.Validator
 
-	f := []string{}
+	if (tf.Format{Name: s.Format}).IsChecked() {
+		validators = append(validators, tf.Format{Name: s.Format})
+	}
 
-	if len(s.Format) > 0 {
-		temp := GetTFValidationFunc(s.Format)
-		if len(temp) > 0 {
-			f = append(f, temp)
-		}
+	if enum := buildEnumValidator(s); enum != nil {
+		validators = append(validators, *enum)
 	}
 
-	if s.Min != nil {
-		bound := *s.Min
+	if s.Type == TypeString && isGoRegexp(s.Pattern) {
+		validators = append(validators, tf.Pattern{Regex: s.Pattern})
+	}
 
-		if t == TypeInteger && s.Format != FormatInt64 {
-			if s.ExclusiveMin {
-				bound++
-			}
+	if s.Min == nil && s.Max == nil {
+		return validators
+	}
+
+	switch {
+	case s.Type == TypeInteger && s.Format != FormatInt64:
+		r := tf.IntRange{ExclusiveMin: s.ExclusiveMin, ExclusiveMax: s.ExclusiveMax}
+
+		if s.Min != nil {
+			min := int64(*s.Min)
+			r.Min = &min
+		}
+
+		if s.Max != nil {
+			max := int64(*s.Max)
+			r.Max = &max
+		}
+
+		validators = append(validators, r)
+	case s.Type == TypeInteger:
+		// int64 values are floats in Terraform, and exclusive bounds of
+		// integers are the next integer in.
+		r := tf.FloatRange{}
 
-			f = append(f, fmt.Sprintf(tf.ValidateFuncIntAtLeast, int(bound)))
-		} else if t == TypeInteger && s.Format == FormatInt64 {
+		if s.Min != nil {
+			min := *s.Min
 			if s.ExclusiveMin {
-				bound++
+				min++
 			}
 
-			f = append(f, fmt.Sprintf(tf.ValidateFuncFloatAtLeast, bound))
-		} else if t == TypeNumber {
-			if s.ExclusiveMin {
-				f = append(f, tf.BuildValidateFuncFloatAtLeastExclusive(bound))
-			} else {
-				f = append(f, fmt.Sprintf(tf.ValidateFuncFloatAtLeast, bound))
+			r.Min = &min
+		}
+
+		if s.Max != nil {
+			max := *s.Max
+			if s.ExclusiveMax {
+				max--
 			}
+
+			r.Max = &max
 		}
+
+		validators = append(validators, r)
+	case s.Type == TypeNumber:
+		validators = append(validators, tf.FloatRange{
+			Min:          s.Min,
+			Max:          s.Max,
+			ExclusiveMin: s.ExclusiveMin,
+			ExclusiveMax: s.ExclusiveMax,
+		})
 	}
 
-	if s.Max != nil {
-		bound := *s.Max
+	return validators
+}
 
-		if t == TypeInteger && s.Format != FormatInt64 {
-			if s.ExclusiveMax {
-				bound--
-			}
+// BuildValidationNotes describes the values accepted by the validation that
+// BuildValidationFunc builds from the same schema, one sentence per rule.
+func BuildValidationNotes(s *openapi3.Schema) []string {
+	return tf.DescribeValidators(BuildValidators(s))
+}
 
-			f = append(f, fmt.Sprintf(tf.ValidateFuncIntAtMost, int(bound)))
-		} else if t == TypeInteger && s.Format == FormatInt64 {
-			if s.ExclusiveMax {
-				bound--
-			}
+// isGoRegexp returns true if pattern is a regular expression the regexp
+// package accepts, which generated code can check values against.
+func isGoRegexp(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
 
-			f = append(f, fmt.Sprintf(tf.ValidateFuncFloatAtMost, bound))
-		} else if t == TypeNumber {
-			if s.ExclusiveMax {
-				f = append(f, tf.BuildValidateFuncFloatAtMostExclusive(bound))
-			} else {
-				f = append(f, fmt.Sprintf(tf.ValidateFuncFloatAtMost, bound))
-			}
+	_, err := regexp.Compile(pattern)
+
+	return err == nil
+}
+
+// enumValues returns the enum values of a schema that can be set in
+// configuration.
+func enumValues(s *openapi3.Schema) []interface{} {
+	values := make([]interface{}, 0, len(s.Enum))
+	for _, v := range s.Enum {
+		if v != nil {
+			values = append(values, v)
 		}
 	}
 
-	if len(f) == 0 {
-		return ""
+	return values
+}
+
+// buildEnumValidator builds a validator that only accepts the enum values of
+// a string or integer schema, or returns nil if there are none.
+func buildEnumValidator(s *openapi3.Schema) *tf.OneOf {
+	values := make([]interface{}, 0, len(s.Enum))
+
+	for _, v := range s.Enum {
+		switch {
+		case v == nil:
+			// null is allowed by nullable enums, and never set in config.
+			continue
+		case s.Type == TypeString:
+			values = append(values, fmt.Sprint(v))
+		case s.Type == TypeInteger && s.Format != FormatInt64:
+			n, ok := v.(float64)
+			if !ok {
+				return nil
+			}
+
+			values = append(values, int64(n))
+		default:
+			return nil
+		}
 	}
 
-	if len(f) == 1 {
-		return fmt.Sprintf("validation.ToDiagFunc(%s)", f[0])
+	if len(values) == 0 {
+		return nil
 	}
 
-	return fmt.Sprintf("validation.ToDiagFunc(validation.All(%s))", strings.Join(f, ","))
+	return &tf.OneOf{Values: values}
 }
 
 // GetTFType returns the Terraform type that corresponds to the given OpenAPI
@@ -191,6 +680,10 @@ func GetTFType(s *openapi3.Schema) (string, error) {
 	case "number":
 		return tf.TypeFloat, nil
 	case "array":
+		if s.UniqueItems {
+			return tf.TypeSet, nil
+		}
+
 		return tf.TypeList, nil
 	case "object":
 		return tf.TypeMap, nil
@@ -202,10 +695,22 @@ func GetTFType(s *openapi3.Schema) (string, error) {
 // GetTFValidationFunc returns a Terraform validation function that corresponds
 // to the given format.
 func GetTFValidationFunc(format string) string {
-	switch format {
-	case "date-time", "date":
-		return tf.ValidateFuncRFC3339Time
+	if f := (tf.Format{Name: format}).SDKv2(); len(f) > 0 {
+		return f[0]
 	}
 
 	return ""
 }
+
+// sortedKeys returns the keys of m in sorted order, so that output and
+// diagnostics are deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
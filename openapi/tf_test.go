@@ -29,13 +29,23 @@ func TestBuildValidationFunc(t *testing.T) {
 		{
 			name: "date format",
 			arg:  &openapi3.Schema{Type: "string", Format: "date"},
-			want: "validation.IsRFC3339Time",
+			want: "validation.StringMatch(regexp.MustCompile(`^\\d{4}-\\d{2}-\\d{2}$`), \"\")",
 		},
 		{
 			name: "date-time format",
 			arg:  &openapi3.Schema{Type: "string", Format: "date-time"},
 			want: "validation.IsRFC3339Time",
 		},
+		{
+			name: "pattern",
+			arg:  &openapi3.Schema{Type: "string", Pattern: "^[a-z]+$"},
+			want: "validation.StringMatch(regexp.MustCompile(`^[a-z]+$`), \"\")",
+		},
+		{
+			name: "pattern Go doesn't support",
+			arg:  &openapi3.Schema{Type: "string", Pattern: "^(?!x)"},
+			want: "",
+		},
 		{
 			name: "int inclusive minimum",
 			arg: &openapi3.Schema{
@@ -77,7 +87,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Format: "float",
 				Min:    internal.Float64Ptr(1),
 			},
-			want: "validation.FloatAtLeast(1.000000)",
+			want: "validation.FloatAtLeast(1)",
 		},
 		{
 			name: "float32 inclusive maximum",
@@ -86,7 +96,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Format: "float",
 				Max:    internal.Float64Ptr(1),
 			},
-			want: "validation.FloatAtMost(1.000000)",
+			want: "validation.FloatAtMost(1)",
 		},
 		// TF doesn't support int64, so we convert to float64.
 		{
@@ -96,7 +106,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Format: "int64",
 				Min:    internal.Float64Ptr(1),
 			},
-			want: "validation.FloatAtLeast(1.000000)",
+			want: "validation.FloatAtLeast(1)",
 		},
 		{
 			name: "int64 inclusive maximum",
@@ -105,7 +115,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Format: "int64",
 				Max:    internal.Float64Ptr(1),
 			},
-			want: "validation.FloatAtMost(1.000000)",
+			want: "validation.FloatAtMost(1)",
 		},
 		{
 			name: "int64 exclusive minimum",
@@ -115,7 +125,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Min:          internal.Float64Ptr(1),
 				ExclusiveMin: true,
 			},
-			want: "validation.FloatAtLeast(2.000000)",
+			want: "validation.FloatAtLeast(2)",
 		},
 		{
 			name: "int64 exclusive maximum",
@@ -125,7 +135,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Max:          internal.Float64Ptr(5),
 				ExclusiveMax: true,
 			},
-			want: "validation.FloatAtMost(4.000000)",
+			want: "validation.FloatAtMost(4)",
 		},
 		{
 			name: "float64 inclusive minimum",
@@ -134,7 +144,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Format: "double",
 				Min:    internal.Float64Ptr(1),
 			},
-			want: "validation.FloatAtLeast(1.000000)",
+			want: "validation.FloatAtLeast(1)",
 		},
 		{
 			name: "float64 inclusive maximum",
@@ -143,7 +153,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Format: "double",
 				Max:    internal.Float64Ptr(1),
 			},
-			want: "validation.FloatAtMost(1.000000)",
+			want: "validation.FloatAtMost(1)",
 		},
 		{
 			name: "float64 exclusive minimum",
@@ -153,7 +163,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Min:          internal.Float64Ptr(1),
 				ExclusiveMin: true,
 			},
-			want: tf.BuildValidateFuncFloatAtLeastExclusive(1),
+			want: "validation.FloatAtLeast(1.0000000000000002)",
 		},
 		{
 			name: "float64 exclusive maximum",
@@ -163,7 +173,7 @@ func TestBuildValidationFunc(t *testing.T) {
 				Max:          internal.Float64Ptr(1),
 				ExclusiveMax: true,
 			},
-			want: tf.BuildValidateFuncFloatAtMostExclusive(1),
+			want: "validation.FloatAtMost(0.9999999999999999)",
 		},
 		{
 			name: "compound validation",
@@ -180,8 +190,14 @@ func TestBuildValidationFunc(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := openapi.BuildValidationFunc(tt.arg); got != tt.want {
-				t.Errorf("BuildValidationFunc() = %v, want %v", got, tt.want)
+			// Validators are always adapted for use as ValidateDiagFunc.
+			want := tt.want
+			if want != "" {
+				want = "validation.ToDiagFunc(" + want + ")"
+			}
+
+			if got := openapi.BuildValidationFunc(tt.arg); got != want {
+				t.Errorf("BuildValidationFunc() = %v, want %v", got, want)
 			}
 		})
 	}
@@ -201,7 +217,7 @@ func TestGetTFValidationFunc(t *testing.T) {
 		{
 			name: "date format",
 			arg:  "date",
-			want: "validation.IsRFC3339Time",
+			want: "validation.StringMatch(regexp.MustCompile(`^\\d{4}-\\d{2}-\\d{2}$`), \"\")",
 		},
 		{
 			name: "date-time format",
@@ -297,6 +313,12 @@ func TestGetTFType(t *testing.T) {
 			want:    tf.TypeList,
 			wantErr: false,
 		},
+		{
+			name:    "unique array",
+			arg:     &openapi3.Schema{Type: "array", UniqueItems: true},
+			want:    tf.TypeSet,
+			wantErr: false,
+		},
 		{
 			name:    "object",
 			arg:     &openapi3.Schema{Type: "object"},
@@ -319,51 +341,222 @@ func TestGetTFType(t *testing.T) {
 }
 
 func TestConvertToTFSchema(t *testing.T) {
+	scope := tf.NewTerrformScope("Test")
+
 	tests := []struct {
 		name       string
 		schemaName string
 		arg        *openapi3.Schema
 		want       *tf.TerraformSchema
-		wantErr    bool
+		wantDiags  internal.Diagnostics
 	}{
 		{
-			name:    "null case",
-			arg:     nil,
-			want:    nil,
-			wantErr: true,
+			name:       "null case",
+			schemaName: "TestSchema",
+			arg:        nil,
+			want:       nil,
+			wantDiags: internal.Diagnostics{
+				{
+					Severity: internal.SeverityError,
+					Message:  "schema 'TestSchema' is nil",
+					Pointer:  "#/components/schemas/TestSchema",
+				},
+			},
 		},
 		{
 			name:       "unknown type",
 			schemaName: "TestSchema",
 			arg: &openapi3.Schema{
-				Type: "unknown",
+				Type: "object",
+				Properties: openapi3.Schemas{
+					"good": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: "unknown"},
+					},
+					"bad/name": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: "other"},
+					},
+				},
+			},
+			want: &tf.TerraformSchema{
+				Scope:         scope,
+				Name:          "TestSchema",
+				NameCamelCase: "TestSchema",
+				NameSnakeCase: "test_schema",
+				Properties:    map[string]tf.TerraformProperty{},
+				Pointer:       "#/components/schemas/TestSchema",
+			},
+			wantDiags: internal.Diagnostics{
+				{
+					Severity: internal.SeverityError,
+					Message:  "unsupported type other",
+					Pointer:  "#/components/schemas/TestSchema/properties/bad~1name",
+				},
+				{
+					Severity: internal.SeverityError,
+					Message:  "unsupported type unknown",
+					Pointer:  "#/components/schemas/TestSchema/properties/good",
+				},
 			},
-			want:    nil,
-			wantErr: true,
 		},
 		{
 			name:       "Happy path",
 			schemaName: "TestSchema",
 			arg: &openapi3.Schema{
-				Type:        "string",
-				Format:      "date-time",
-				Description: "test",
+				Type: "object",
+				Properties: openapi3.Schemas{
+					"createdAt": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Format:      "date-time",
+							Description: "test",
+						},
+					},
+				},
+			},
+			want: &tf.TerraformSchema{
+				Scope:         scope,
+				Name:          "TestSchema",
+				NameCamelCase: "TestSchema",
+				NameSnakeCase: "test_schema",
+				Properties: map[string]tf.TerraformProperty{
+					"created_at": {
+						Type:         tf.TypeString,
+						Optional:     internal.BoolPtr(true),
+						Description:  internal.StringPtr("test"),
+						ValidateFunc: internal.StringPtr("validation.ToDiagFunc(validation.IsRFC3339Time)"),
+						Validators:   []tf.Validator{tf.Format{Name: "date-time"}},
+						OriginalName: "createdAt",
+						Pointer:      "#/components/schemas/TestSchema/properties/createdAt",
+					},
+				},
+				HasValidateFuncs: true,
+				Pointer:          "#/components/schemas/TestSchema",
+			},
+		},
+		{
+			name:       "validation failure",
+			schemaName: "TestSchema",
+			arg: &openapi3.Schema{
+				Type: "object",
+				Properties: openapi3.Schemas{
+					"héllo": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: "string"},
+					},
+				},
+			},
+			want: &tf.TerraformSchema{
+				Scope:         scope,
+				Name:          "TestSchema",
+				NameCamelCase: "TestSchema",
+				NameSnakeCase: "test_schema",
+				Properties: map[string]tf.TerraformProperty{
+					"héllo": {
+						Type:         tf.TypeString,
+						Optional:     internal.BoolPtr(true),
+						OriginalName: "héllo",
+						Pointer:      "#/components/schemas/TestSchema/properties/héllo",
+					},
+				},
+				Pointer: "#/components/schemas/TestSchema",
+			},
+			wantDiags: internal.Diagnostics{
+				{
+					Severity: internal.SeverityError,
+					Message:  "property 'héllo': name may only contain lowercase alphanumeric characters and underscores",
+					Pointer:  "#/components/schemas/TestSchema/properties/héllo",
+				},
+			},
+		},
+		{
+			name:       "reserved and colliding names",
+			schemaName: "TestSchema",
+			arg: &openapi3.Schema{
+				Type: "object",
+				Properties: openapi3.Schemas{
+					"id": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: "string"},
+					},
+					"fooBar": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: "string"},
+					},
+					"foo_bar": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: "string"},
+					},
+				},
 			},
 			want: &tf.TerraformSchema{
-				Name: "TestSchema",
-				Properties: map[string]interface{}{
-					"Type":           tf.TypeString,
-					"ValidationFunc": "validation.IsRFC3339Time",
-					"Description":    "test",
+				Scope:         scope,
+				Name:          "TestSchema",
+				NameCamelCase: "TestSchema",
+				NameSnakeCase: "test_schema",
+				Properties: map[string]tf.TerraformProperty{
+					"foo_bar": {
+						Type:         tf.TypeString,
+						Optional:     internal.BoolPtr(true),
+						OriginalName: "foo_bar",
+						Pointer:      "#/components/schemas/TestSchema/properties/foo_bar",
+					},
+					"test_schema_foo_bar": {
+						Type:         tf.TypeString,
+						Optional:     internal.BoolPtr(true),
+						OriginalName: "fooBar",
+						Pointer:      "#/components/schemas/TestSchema/properties/fooBar",
+					},
+					"test_schema_id": {
+						Type:         tf.TypeString,
+						Optional:     internal.BoolPtr(true),
+						OriginalName: "id",
+						Pointer:      "#/components/schemas/TestSchema/properties/id",
+					},
+				},
+				Pointer: "#/components/schemas/TestSchema",
+			},
+			wantDiags: internal.Diagnostics{
+				{
+					Severity: internal.SeverityWarning,
+					Message:  "attribute name 'foo_bar' collides with another property; renamed to 'test_schema_foo_bar'",
+					Pointer:  "#/components/schemas/TestSchema/properties/fooBar",
+				},
+				{
+					Severity: internal.SeverityWarning,
+					Message:  "attribute name 'id' may only be a read-only string; renamed to 'test_schema_id'",
+					Pointer:  "#/components/schemas/TestSchema/properties/id",
 				},
 			},
 		},
+		{
+			name:       "read-only id",
+			schemaName: "TestSchema",
+			arg: &openapi3.Schema{
+				Type: "object",
+				Properties: openapi3.Schemas{
+					"id": &openapi3.SchemaRef{
+						Value: &openapi3.Schema{Type: "string", ReadOnly: true},
+					},
+				},
+			},
+			want: &tf.TerraformSchema{
+				Scope:         scope,
+				Name:          "TestSchema",
+				NameCamelCase: "TestSchema",
+				NameSnakeCase: "test_schema",
+				Properties: map[string]tf.TerraformProperty{
+					"id": {
+						Type:         tf.TypeString,
+						Computed:     internal.BoolPtr(true),
+						OriginalName: "id",
+						Pointer:      "#/components/schemas/TestSchema/properties/id",
+					},
+				},
+				Pointer: "#/components/schemas/TestSchema",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := openapi.ConvertToTFSchema(tt.schemaName, tt.arg)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("ConvertToTFSchema() error = %v, wantErr %v", err, tt.wantErr)
+			got, diags := openapi.ConvertToTFSchema(tt.schemaName, scope, tt.arg)
+			if !reflect.DeepEqual(diags, tt.wantDiags) {
+				t.Errorf("ConvertToTFSchema() diags = %v, want %v", diags, tt.wantDiags)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
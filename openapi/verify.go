@@ -0,0 +1,334 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/stevenpaz/tf-schema-gen/internal"
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// verifyModulePath is the module path of the temporary verification module.
+const verifyModulePath = "tf-schema-gen/verify"
+
+const verifyModTemplate = `module {{.ModulePath}}
+
+go 1.20
+
+require github.com/hashicorp/terraform-plugin-sdk/v2 {{.SDKVersion}}
+{{if .SDKPath}}
+replace github.com/hashicorp/terraform-plugin-sdk/v2 => {{.SDKPath}}
+{{end}}`
+
+const verifyHarnessTemplate = `// Code generated by github.com/stevenpaz/tf-schema-gen; DO NOT EDIT.
+package {{packageName}}
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestInternalValidate(t *testing.T) {
+	resources := map[string]func() *schema.Resource{
+		{{range .Scope.Schemas -}}
+		{{if .Resource -}}
+		"{{.NameCamelCase}}": {{qualify .}}Resource{{.NameCamelCase}},
+		{{- else -}}
+		"{{.NameCamelCase}}": func() *schema.Resource {
+			return blockResource({{qualify .}}Get{{.NameCamelCase}}Schema())
+		},
+		{{- end}}
+		{{end}}
+	}
+
+	for name, resource := range resources {
+		name, resource := name, resource
+		t.Run(name, func(t *testing.T) {
+			if err := resource().InternalValidate(nil, true); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+{{- if and .Scope.Provider .Scope.HasResources}}
+
+func TestProviderInternalValidate(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Error(err)
+	}
+}
+{{- end}}
+
+// blockResource returns a resource with the schema of a nested block, which
+// has no resource of its own.
+func blockResource(m map[string]*schema.Schema) *schema.Resource {
+	r := &schema.Resource{
+		Schema:        m,
+		CreateContext: schema.NoopContext,
+		ReadContext:   schema.NoopContext,
+		DeleteContext: schema.NoopContext,
+	}
+
+	// The SDK rejects Update on resources without updatable fields.
+	if updatable(m) {
+		r.UpdateContext = schema.NoopContext
+	}
+
+	return r
+}
+
+func updatable(m map[string]*schema.Schema) bool {
+	for _, s := range m {
+		if !s.ForceNew && (!s.Computed || s.Optional) {
+			return true
+		}
+	}
+
+	return false
+}
+`
+
+// verifyHarnessFile is the file name of the generated verification harness.
+const verifyHarnessFile = "tf_schema_gen_verify_test.go"
+
+// verifyTests are the tests of the verification harness, as a regular
+// expression alternation.
+const verifyTests = "TestInternalValidate|TestProviderInternalValidate"
+
+var (
+	verifyModTmpl = template.Must(template.New("mod").Parse(verifyModTemplate))
+	// verifyHarnessTmpl runs in the root package, and refers to the schemas
+	// of the packages the code may be split into.
+	verifyHarnessTmpl = template.Must(
+		template.New("harness").Funcs(fileFuncs).Parse(verifyHarnessTemplate))
+)
+
+var (
+	// buildErrorLine matches compiler errors such as
+	// ./origin_schema.go:12:3: undefined: errors.
+	buildErrorLine = regexp.MustCompile(`^(?:.*/)?([^/\s]+\.go):\d+:\d+: (.*)$`)
+	// testErrorLine matches messages logged by the harness.
+	testErrorLine = regexp.MustCompile(`^\s+` +
+		regexp.QuoteMeta(verifyHarnessFile) + `:\d+: (.*)$`)
+)
+
+// testEvent is an event printed by go test -json.
+type testEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// VerifyGeneratedCode compiles the rendered files in a temporary module
+// against the Terraform SDK and runs schema.Resource.InternalValidate on the
+// generated resource of every schema, or on a resource with the schema of
+// those that are only nested blocks. The paths of files are relative to the
+// output folder, which is the root of the module. Failures are reported
+// against the OpenAPI schema the code was generated from, and any other
+// failure of the verification run as an error of its own.
+func VerifyGeneratedCode(
+	scope *tf.TerraformScope,
+	files []internal.File,
+	opts Options,
+) (internal.Diagnostics, error) {
+	var diags internal.Diagnostics
+
+	dir, err := os.MkdirTemp("", "tf-schema-gen-verify-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating verification module: %w", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	// schemasByFile maps generated file names back to their schema.
+	schemasByFile := make(map[string]*tf.TerraformSchema, len(files))
+	schemasByName := make(map[string]*tf.TerraformSchema, len(scope.Schemas))
+
+	for _, ts := range scope.Schemas {
+		schemasByFile[schemaFileName(ts)] = ts
+		schemasByName[ts.NameCamelCase] = ts
+	}
+
+	if err := writeVerifyModule(dir, scope, files, opts); err != nil {
+		return nil, err
+	}
+
+	if err := tidyVerifyModule(dir); err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	// Only the harness runs; the unit tests of resources are left to the
+	// provider's own test run.
+	test := exec.Command("go", "test", "-json", "-count=1",
+		"-run", "^("+verifyTests+")$", "./...")
+	test.Dir = dir
+	test.Stdout = &stdout
+	test.Stderr = &stderr
+
+	// A failing test or build is reported through the output parsed below, so
+	// only an empty output means go test could not run at all.
+	runErr := test.Run()
+	if runErr != nil && stdout.Len() == 0 && stderr.Len() == 0 {
+		return nil, fmt.Errorf("error running go test: %w", runErr)
+	}
+
+	output := make(map[string][]string)
+	buildOutput := stderr.String()
+	testOutput := ""
+
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// Anything that isn't JSON is build output.
+			buildOutput += scanner.Text() + "\n"
+			continue
+		}
+
+		switch event.Action {
+		case "build-output":
+			buildOutput += event.Output
+		case "output":
+			testOutput += event.Output
+
+			line := strings.TrimRight(event.Output, "\n")
+			if m := testErrorLine.FindStringSubmatch(line); m != nil {
+				output[event.Test] = append(output[event.Test], m[1])
+			}
+		case "fail":
+			if event.Test == "TestProviderInternalValidate" {
+				for _, msg := range output[event.Test] {
+					diags.AddError("", fmt.Sprintf(
+						"generated provider fails InternalValidate: %s", msg))
+				}
+			}
+
+			name := strings.TrimPrefix(event.Test, "TestInternalValidate/")
+			if ts, ok := schemasByName[name]; ok {
+				for _, msg := range output[event.Test] {
+					diags.AddError(ts.Pointer, fmt.Sprintf(
+						"generated schema fails InternalValidate: %s", msg))
+				}
+			}
+		}
+	}
+
+	for _, line := range strings.Split(buildOutput, "\n") {
+		m := buildErrorLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		pointer := ""
+		if ts, ok := schemasByFile[m[1]]; ok {
+			pointer = ts.Pointer
+		}
+
+		diags.AddError(pointer, fmt.Sprintf(
+			"generated code does not compile: %s: %s", m[1], m[2]))
+	}
+
+	// Failures the output above doesn't explain, like a panic, are still
+	// failures.
+	if runErr != nil && !diags.HasErrors() {
+		diags.AddError("", fmt.Sprintf("generated code fails verification: "+
+			"%v\n%s", runErr, strings.TrimSpace(buildOutput+testOutput)))
+	}
+
+	return diags, nil
+}
+
+// tidyVerifyModule resolves the dependencies of the verification module in
+// dir. They are looked up in the module cache first, so verifying needs no
+// network once the SDK has been downloaded, e.g. by building this generator.
+// Only if that fails are they resolved through the Go module proxy.
+func tidyVerifyModule(dir string) error {
+	offline := exec.Command("go", "mod", "tidy")
+	offline.Dir = dir
+	offline.Env = append(os.Environ(), "GOPROXY=off")
+
+	if err := offline.Run(); err == nil {
+		return nil
+	}
+
+	tidy := exec.Command("go", "mod", "tidy")
+	tidy.Dir = dir
+
+	if out, err := tidy.CombinedOutput(); err != nil {
+		return fmt.Errorf("error resolving the Terraform SDK: %w\n%s",
+			err, out)
+	}
+
+	return nil
+}
+
+// writeVerifyModule writes the go.mod, the generated files and the harness of
+// the verification module to dir.
+func writeVerifyModule(
+	dir string,
+	scope *tf.TerraformScope,
+	files []internal.File,
+	opts Options,
+) error {
+	sdkPath := opts.SDKPath
+	if sdkPath != "" {
+		abs, err := filepath.Abs(sdkPath)
+		if err != nil {
+			return fmt.Errorf("error resolving SDK path: %w", err)
+		}
+
+		sdkPath = abs
+	}
+
+	// Split packages import each other by the import path of the output
+	// folder, so it has to be the module path.
+	modulePath := verifyModulePath
+	if scope.IsSplit() {
+		modulePath = opts.ImportPath
+	}
+
+	var mod bytes.Buffer
+
+	err := verifyModTmpl.Execute(&mod, map[string]string{
+		"ModulePath": modulePath,
+		"SDKVersion": tf.SDKVersion,
+		"SDKPath":    sdkPath,
+	})
+	if err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+
+	harness, err := renderGo(verifyHarnessTmpl, newGoFile(scope, nil),
+		map[string]interface{}{"Scope": scope})
+	if err != nil {
+		return fmt.Errorf("error rendering verification harness: %w", err)
+	}
+
+	moduleFiles := []internal.File{
+		{Path: filepath.Join(dir, "go.mod"), Data: mod.Bytes()},
+		{Path: filepath.Join(dir, verifyHarnessFile), Data: harness},
+	}
+
+	for _, f := range files {
+		path := filepath.Join(dir, f.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("error creating verification module: %w", err)
+		}
+
+		moduleFiles = append(moduleFiles, internal.File{Path: path, Data: f.Data})
+	}
+
+	return internal.WriteFilesAtomic(moduleFiles)
+}
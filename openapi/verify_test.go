@@ -0,0 +1,145 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/openapi"
+)
+
+const verifySpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Origin:
+      type: object
+      properties:
+        name:
+          type: string
+        port:
+          type: integer
+          minimum: 1
+`
+
+// TestGenerateFiles_Verify tests that generated code is compiled and
+// validated against the Terraform SDK, and that failures are reported in the
+// OpenAPI document.
+func TestGenerateFiles_Verify(t *testing.T) {
+	if testing.Short() {
+		t.Skip("verification builds a Go module")
+	}
+
+	// The SDK is a dependency of this module, so it is in the module cache.
+	t.Setenv("GOPROXY", "off")
+
+	builtin, err := os.ReadFile(filepath.Join("templates", "schema.go.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broken := t.TempDir()
+	schemaTmpl := strings.Replace(string(builtin),
+		"ValidateDiagFunc: {{.}},",
+		`ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast("1")),`,
+		1)
+
+	err = os.WriteFile(filepath.Join(broken, "schema.go.tmpl"),
+		[]byte(schemaTmpl), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	panics := t.TempDir()
+	schemaTmpl = strings.Replace(string(builtin),
+		`return {{template "properties" .}}`, `panic("broken")`, 1)
+
+	err = os.WriteFile(filepath.Join(panics, "schema.go.tmpl"),
+		[]byte(schemaTmpl), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		spec         string
+		templatesDir string
+		wantErr      string
+	}{
+		{
+			name: "valid",
+			spec: verifySpec,
+		},
+		{
+			name: "valid resource",
+			spec: parentParamSpec,
+		},
+		{
+			name:         "bad validator",
+			spec:         verifySpec,
+			templatesDir: broken,
+			wantErr:      "generated code does not compile: origin_schema.go",
+		},
+		{
+			name:         "panic",
+			spec:         strings.Replace(verifySpec, "minimum: 1", "", 1),
+			templatesDir: panics,
+			wantErr:      "generated code fails verification",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, diags, err := openapi.GenerateFiles(
+				strings.NewReader(tt.spec), "spec.yaml", "out",
+				openapi.Options{
+					Verify:       true,
+					TemplatesDir: tt.templatesDir,
+				})
+			if err != nil {
+				if strings.Contains(err.Error(), "resolving the Terraform SDK") {
+					t.Skipf("Terraform SDK not available: %v", err)
+				}
+
+				t.Fatal(err)
+			}
+
+			if tt.wantErr == "" {
+				if diags.HasErrors() {
+					t.Fatalf("unexpected errors: %v", diags)
+				}
+
+				return
+			}
+
+			found := false
+
+			for _, d := range diags {
+				if !strings.Contains(d.Message, tt.wantErr) {
+					continue
+				}
+
+				found = true
+
+				if d.Pointer == "" {
+					continue
+				}
+
+				if d.File != "spec.yaml" || d.Line == 0 {
+					t.Errorf("diagnostic at %s:%d, want a line of spec.yaml",
+						d.File, d.Line)
+				}
+			}
+
+			if !found {
+				t.Errorf("diags = %v, want an error containing %q",
+					diags, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package tf
 
-import "fmt"
+// SDKVersion is the version of terraform-plugin-sdk/v2 generated code targets.
+const SDKVersion = "v2.26.1"
+
+// ProviderName is the name of the provider generated resources belong to, and
+// the prefix of their resource type names.
+const ProviderName = "edgio"
 
 // Constants for the primitive terraform types.
 const (
@@ -9,57 +14,38 @@ const (
 	TypeInt    = "TypeInt"
 	TypeFloat  = "TypeFloat"
 	TypeList   = "TypeList"
+	TypeSet    = "TypeSet"
 	TypeMap    = "TypeMap"
 )
 
-// Constants for Terraform SDKv2 validation functions.
-const (
-	ValidateFuncRFC3339Time  = "validation.IsRFC3339Time"
-	ValidateFuncIntAtLeast   = "validation.IntAtLeast(%d)"
-	ValidateFuncIntAtMost    = "validation.IntAtMost(%d)"
-	ValidateFuncFloatAtLeast = "validation.FloatAtLeast(%f)"
-	ValidateFuncFloatAtMost  = "validation.FloatAtMost(%f)"
-
-	ValidateFuncFloatAtLeastExclusive = `func(i interface{}, p string) (s []string, es []error) {
-	v, ok := i.(float64)
-	if !ok {
-		es = append(es, errors.New("expected type of float"))
-		return
-	}
-
-	if v <= %f {
-		es = append(es, errors.New("expected more than (%f)"))
-		return
-	}
-
-	return
-}`
-
-	ValidateFuncFloatAtMostExclusive = `func(i interface{}, p string) (s []string, es []error) {
-	v, ok := i.(float64)
-	if !ok {
-		es = append(es, errors.New("expected type of float"))
-		return
-	}
+// ReservedAttributeNames are attribute names Terraform reserves for itself and
+// which resources may not declare.
+var ReservedAttributeNames = []string{
+	"connection",
+	"count",
+	"depends_on",
+	"lifecycle",
+	"provider",
+	"provisioner",
+}
 
-	if v >= %f {
-		es = append(es, errors.New("expected less than (%f)"))
-		return
-	}
+// IDAttributeName is the name of the attribute the SDK stores the ID of a
+// resource in. Resources may declare it, but only as a computed string.
+const IDAttributeName = "id"
 
-	return
-}`
+// Constants for Terraform SDKv2 validation functions.
+const (
+	ValidateFuncRFC3339Time   = "validation.IsRFC3339Time"
+	ValidateFuncIntAtLeast    = "validation.IntAtLeast(%d)"
+	ValidateFuncIntAtMost     = "validation.IntAtMost(%d)"
+	ValidateFuncFloatAtLeast  = "validation.FloatAtLeast(%s)"
+	ValidateFuncFloatAtMost   = "validation.FloatAtMost(%s)"
+	ValidateFuncStringInSlice = "validation.StringInSlice([]string{%s}, false)"
+	ValidateFuncIntInSlice    = "validation.IntInSlice([]int{%s})"
+	ValidateFuncStringMatch   = `validation.StringMatch(regexp.MustCompile(%s), "")`
 )
 
 // Constants for Custom Validation Functions.
 const (
 	ValidateFuncMatchRegExPattern = "MatchRegExPattern"
 )
-
-func BuildValidateFuncFloatAtMostExclusive(max float64) string {
-	return fmt.Sprintf(ValidateFuncFloatAtMostExclusive, max, max)
-}
-
-func BuildValidateFuncFloatAtLeastExclusive(min float64) string {
-	return fmt.Sprintf(ValidateFuncFloatAtLeastExclusive, min, min)
-}
@@ -0,0 +1,61 @@
+package tf
+
+// Package is a Go package the code of a scope is split into, next to the root
+// package the provider is generated in.
+type Package struct {
+	// Name is the name of the package in its package clause.
+	Name string
+	// Path is the folder of the package, relative to the folder of the root
+	// package, e.g. internal/service/cdn.
+	Path string
+	// ImportPath is the path other packages import the package by.
+	ImportPath string
+	// Tags are the OpenAPI tags of the operations whose schemas the package
+	// holds.
+	Tags []string
+	// Shared is true for the package that holds the API client and the
+	// schemas more than one package uses. Other packages import it, so the
+	// names it declares for them are exported.
+	Shared bool
+}
+
+// IsSplit returns true if the code of the scope is split into packages.
+func (ts *TerraformScope) IsSplit() bool {
+	return len(ts.Packages) > 0
+}
+
+// SharedPackage returns the package other packages of the scope import, or
+// nil if the code of the scope is not split.
+func (ts *TerraformScope) SharedPackage() *Package {
+	for _, p := range ts.Packages {
+		if p.Shared {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// PackageName returns the name of a package of the scope, where nil is the
+// root package.
+func (ts *TerraformScope) PackageName(p *Package) string {
+	if p == nil {
+		return ts.GoPackage
+	}
+
+	return p.Name
+}
+
+// SchemasIn returns the schemas of the scope in a package, where nil is the
+// root package.
+func (ts *TerraformScope) SchemasIn(p *Package) []*TerraformSchema {
+	var schemas []*TerraformSchema
+
+	for _, schema := range ts.Schemas {
+		if schema.Package == p {
+			schemas = append(schemas, schema)
+		}
+	}
+
+	return schemas
+}
@@ -0,0 +1,67 @@
+package tf
+
+import "strings"
+
+// Constants for the ways credentials authenticate API requests.
+const (
+	CredentialBearer            = "bearer"
+	CredentialBasic             = "basic"
+	CredentialAPIKey            = "apiKey"
+	CredentialClientCredentials = "clientCredentials"
+)
+
+// Provider describes the provider block resources are configured through.
+type Provider struct {
+	// BaseURL is the attribute that holds the URL the API is called at.
+	BaseURL     ProviderAttribute
+	Credentials []Credential
+}
+
+// ProviderAttribute is a string attribute of the provider block.
+type ProviderAttribute struct {
+	Name        string
+	Description string
+	// Required is true for attributes that have no default.
+	Required bool
+	// Sensitive hides the value of the attribute in plans and logs.
+	Sensitive bool
+	// Default is the value used if neither the attribute nor its environment
+	// variable is set.
+	Default string
+}
+
+// Credential is a way to authenticate requests to the API with the values of
+// provider attributes.
+type Credential struct {
+	// Type is one of the Credential constants.
+	Type string
+	// Attributes hold the values of the credential: the token for bearer,
+	// the user and password for basic, the key for apiKey, and the client ID
+	// and secret for clientCredentials.
+	Attributes []ProviderAttribute
+	// In is where API keys are sent: header, query or cookie.
+	In string
+	// Name is the name of the header, query parameter or cookie API keys
+	// are sent in.
+	Name string
+	// TokenURL is where clientCredentials exchange the client ID and secret
+	// for a token.
+	TokenURL string
+	Scopes   []string
+}
+
+// Attributes returns every attribute of the provider block.
+func (p Provider) Attributes() []ProviderAttribute {
+	attrs := []ProviderAttribute{p.BaseURL}
+	for _, c := range p.Credentials {
+		attrs = append(attrs, c.Attributes...)
+	}
+
+	return attrs
+}
+
+// EnvVar returns the environment variable the attribute defaults to, e.g.
+// EDGIO_API_TOKEN.
+func (pa ProviderAttribute) EnvVar() string {
+	return strings.ToUpper(ProviderName + "_" + pa.Name)
+}
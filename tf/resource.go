@@ -0,0 +1,126 @@
+package tf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Operation is an API operation a resource is managed with.
+type Operation struct {
+	Method string
+	// Path is the path template of the operation, e.g. /origins/{id}.
+	Path string
+	// Params maps the parameters of Path to the attributes that hold their
+	// values. The parameter that identifies the resource maps to an empty
+	// string, as its value is the resource ID.
+	Params map[string]string
+	// Status is the documented success status code of the operation, or 0 if
+	// none is documented.
+	Status int
+	// Response is an example response body, decoded from JSON.
+	Response interface{}
+	// Async is true for long-running operations, whose status has to be
+	// polled until they are done.
+	Async bool
+}
+
+// ResourceOperations are the operations that create, read, update and delete
+// the API object a schema describes. Update is nil for objects that can't be
+// changed once created.
+type ResourceOperations struct {
+	Create *Operation
+	Read   *Operation
+	Update *Operation
+	Delete *Operation
+	// IDField is the property of API responses that holds the resource ID.
+	IDField string
+}
+
+// Operations returns the operations that are set, in CRUD order.
+func (ro ResourceOperations) Operations() []*Operation {
+	ops := make([]*Operation, 0, 4)
+
+	for _, op := range []*Operation{ro.Create, ro.Read, ro.Update, ro.Delete} {
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
+
+// IsAsync returns true if any operation of the resource is long-running.
+func (ro ResourceOperations) IsAsync() bool {
+	for _, op := range ro.Operations() {
+		if op.Async {
+			return true
+		}
+	}
+
+	return false
+}
+
+var pathParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// PathParams returns the names of the parameters of the operation's path in
+// the order they appear.
+func (op Operation) PathParams() []string {
+	matches := pathParam.FindAllStringSubmatch(op.Path, -1)
+
+	params := make([]string, 0, len(matches))
+	for _, m := range matches {
+		params = append(params, m[1])
+	}
+
+	return params
+}
+
+// PathPattern returns a regular expression that matches the paths of
+// requests to the operation.
+func (op Operation) PathPattern() string {
+	parts := pathParam.Split(op.Path, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return "^" + strings.Join(parts, "[^/]+") + "$"
+}
+
+// PathFormat returns the path of the operation as a format string with a %s
+// verb in place of each parameter.
+func (op Operation) PathFormat() string {
+	return pathParam.ReplaceAllLiteralString(
+		strings.ReplaceAll(op.Path, "%", "%%"), "%s")
+}
+
+// String implements fmt.Stringer.
+func (op Operation) String() string {
+	return fmt.Sprintf("%s %s", op.Method, op.Path)
+}
+
+// ImportAttributes returns the attributes that hold the parameters of the
+// read operation's path other than the resource ID, in path order.
+func (ro ResourceOperations) ImportAttributes() []string {
+	var attrs []string
+
+	for _, param := range ro.Read.PathParams() {
+		if attr := ro.Read.Params[param]; attr != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	return attrs
+}
+
+// ImportIDFormat returns the format of the IDs resources are imported by: the
+// parameters of the read operation's path separated by slashes, e.g.
+// {property_id}/{id}.
+func (ro ResourceOperations) ImportIDFormat() string {
+	params := ro.Read.PathParams()
+	for i, param := range params {
+		params[i] = "{" + param + "}"
+	}
+
+	return strings.Join(params, "/")
+}
@@ -0,0 +1,49 @@
+package tf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// TestOperation_Paths tests the path helpers of Operation.
+func TestOperation_Paths(t *testing.T) {
+	t.Parallel()
+
+	op := tf.Operation{Path: "/properties/{property_id}/rules/{id}.json"}
+
+	if got, want := op.PathParams(), []string{"property_id", "id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PathParams() = %v, want %v", got, want)
+	}
+
+	if got, want := op.PathPattern(), `^/properties/[^/]+/rules/[^/]+\.json$`; got != want {
+		t.Errorf("PathPattern() = %q, want %q", got, want)
+	}
+
+	if got, want := op.PathFormat(), "/properties/%s/rules/%s.json"; got != want {
+		t.Errorf("PathFormat() = %q, want %q", got, want)
+	}
+}
+
+// TestResourceOperations_Import tests the import ID helpers of
+// ResourceOperations.
+func TestResourceOperations_Import(t *testing.T) {
+	t.Parallel()
+
+	ops := tf.ResourceOperations{Read: &tf.Operation{
+		Path: "/properties/{property_id}/rules/{id}",
+		Params: map[string]string{
+			"property_id": "property",
+			"id":          "",
+		},
+	}}
+
+	if got, want := ops.ImportAttributes(), []string{"property"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportAttributes() = %v, want %v", got, want)
+	}
+
+	if got, want := ops.ImportIDFormat(), "{property_id}/{id}"; got != want {
+		t.Errorf("ImportIDFormat() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,12 @@
 package tf
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/stevenpaz/tf-schema-gen/internal"
 )
 
@@ -9,7 +15,16 @@ type TerraformScope struct {
 	Name          string
 	NameCamelCase string
 	NameSnakeCase string
-	Schemas       []*TerraformSchema
+	// GoPackage is the name of the root package of the generated code.
+	GoPackage string
+	Schemas   []*TerraformSchema
+	// Provider describes the provider block of the API. It is nil for scopes
+	// not converted from a whole document.
+	Provider *Provider
+	// Packages are the packages the code of the scope is split into, besides
+	// the root package. It is empty if all code is generated in the root
+	// package.
+	Packages []*Package
 }
 
 // NewTerrformScope creates a new TerraformScope.
@@ -18,6 +33,7 @@ func NewTerrformScope(name string) *TerraformScope {
 		Name:          name,
 		NameCamelCase: internal.ToCamelCase(name),
 		NameSnakeCase: internal.ToSnakeCase(name),
+		GoPackage:     internal.ToPackageName(name),
 		Schemas:       make([]*TerraformSchema, 0),
 	}
 }
@@ -31,14 +47,66 @@ func (ts *TerraformScope) AddSchema(schema *TerraformSchema) {
 	ts.Schemas = append(ts.Schemas, schema)
 }
 
+// SortSchemas sorts the schemas of the TerraformScope by name.
+func (ts *TerraformScope) SortSchemas() {
+	sort.Slice(ts.Schemas, func(i, j int) bool {
+		return ts.Schemas[i].Name < ts.Schemas[j].Name
+	})
+}
+
+// HasResources returns true if any schema of the TerraformScope is managed as
+// a resource.
+func (ts *TerraformScope) HasResources() bool {
+	for _, schema := range ts.Schemas {
+		if schema.Resource != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasAsyncResources returns true if any resource of the TerraformScope has
+// long-running operations.
+func (ts *TerraformScope) HasAsyncResources() bool {
+	for _, schema := range ts.Schemas {
+		if schema.Resource != nil && schema.Resource.IsAsync() {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Schema represents a Terraform Schema.
 type TerraformSchema struct {
-	Scope            *TerraformScope
-	Name             string
+	Scope *TerraformScope
+	Name  string
+	// NameCamelCase is the name as a Go identifier, which the names of the
+	// generated functions and constants of the schema are derived from.
 	NameCamelCase    string
 	NameSnakeCase    string
+	Description      string
 	Properties       map[string]TerraformProperty
 	HasValidateFuncs bool
+	// Inline is true for nested schemas declared inline in a property, which
+	// have no schema function of their own.
+	Inline bool
+	// Example is the example payload of the OpenAPI schema, decoded from
+	// JSON.
+	Example interface{}
+	// Resource holds the API operations that manage the schema as a
+	// resource. It is nil for schemas that are only used as nested blocks.
+	Resource *ResourceOperations
+	// Decisions are the inferences made about the schema that reviewers of
+	// the generated code should confirm.
+	Decisions []Decision
+	// Pointer is the JSON pointer of the OpenAPI schema this was converted
+	// from.
+	Pointer string
+	// Package is the package the code of the schema is generated in, or nil
+	// for the root package of the scope.
+	Package *Package
 }
 
 // TerraformProperty represents a property of a Terraform Schema.
@@ -49,6 +117,50 @@ type TerraformProperty struct {
 	Computed     *bool
 	Description  *string
 	ValidateFunc *string
+	Elem         *TerraformElem
+	Default      interface{}
+	MaxItems     *int
+	MinItems     *int
+	// Validators are the rules the values of the property must follow.
+	// ValidateFunc is their SDKv2 code.
+	Validators []Validator
+	// Object is true for nested blocks whose API value is a single object
+	// rather than an array.
+	Object bool
+	// ForceNew is true for attributes the API can't change once the
+	// resource is created.
+	ForceNew bool
+	// Enum holds the values ValidateFunc accepts, if it only accepts a fixed
+	// set of them.
+	Enum []interface{}
+	// Example is the example value of the OpenAPI property, decoded from JSON.
+	Example interface{}
+	// OriginalName is the name of the property in the API, which may differ
+	// from its attribute name when the attribute had to be renamed.
+	OriginalName string
+	// PathParam is true for attributes that hold a parameter of the
+	// resource's paths which no property holds. Their values are only sent
+	// in paths, never in request bodies.
+	PathParam bool
+	// Pointer is the JSON pointer of the OpenAPI property this was converted
+	// from.
+	Pointer string
+}
+
+// Decision is an inference the generator made about an attribute.
+type Decision struct {
+	Attribute string
+	// Decision is what was decided, e.g. ForceNew.
+	Decision string
+	// Reason explains what in the OpenAPI document the decision is based on.
+	Reason string
+}
+
+// TerraformElem represents the element of a list, set or map property. Either
+// Type is set for primitive elements, or Schema for nested blocks.
+type TerraformElem struct {
+	Type   string
+	Schema *TerraformSchema
 }
 
 // NewTerrformSchema creates a new TerraformSchema.
@@ -56,7 +168,7 @@ func NewTerrformSchema(name string, scope *TerraformScope) *TerraformSchema {
 	return &TerraformSchema{
 		Scope:         scope,
 		Name:          name,
-		NameCamelCase: internal.ToCamelCase(name),
+		NameCamelCase: internal.ToIdentifier(name),
 		NameSnakeCase: internal.ToSnakeCase(name),
 		Properties:    make(map[string]TerraformProperty),
 	}
@@ -92,10 +204,133 @@ func (tp *TerraformProperty) SetValidateFunc(validateFunc string) {
 	}
 }
 
+// SetElemType sets a primitive element type on a collection property.
+func (tp *TerraformProperty) SetElemType(t string) {
+	tp.Elem = &TerraformElem{Type: t}
+}
+
+// SetElemSchema sets a nested block element on a collection property.
+func (tp *TerraformProperty) SetElemSchema(schema *TerraformSchema) {
+	tp.Elem = &TerraformElem{Schema: schema}
+}
+
+// SetDefault sets the default value of the TerraformProperty.
+func (tp *TerraformProperty) SetDefault(value interface{}) {
+	tp.Default = value
+}
+
+// SetMaxItems sets the maximum number of items of a collection property.
+func (tp *TerraformProperty) SetMaxItems(n int) {
+	tp.MaxItems = &n
+}
+
+// SetMinItems sets the minimum number of items of a collection property.
+func (tp *TerraformProperty) SetMinItems(n int) {
+	tp.MinItems = &n
+}
+
+// AddProp adds a property under the given attribute name, replacing any
+// property already added under that name.
 func (ts *TerraformSchema) AddProp(name string, prop *TerraformProperty) {
 	ts.Properties[name] = *prop
 }
 
+// ResourceName returns the Terraform resource type name of the schema.
+func (ts TerraformSchema) ResourceName() string {
+	return ProviderName + "_" + ts.NameSnakeCase
+}
+
+// InlineSchemas returns the nested blocks declared inline in the schema, and
+// recursively in them, in the order of their properties.
+func (ts TerraformSchema) InlineSchemas() []*TerraformSchema {
+	var schemas []*TerraformSchema
+
+	names := make([]string, 0, len(ts.Properties))
+	for name := range ts.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := ts.Properties[name]
+		if prop.Elem != nil && prop.Elem.Schema != nil && prop.Elem.Schema.Inline {
+			schemas = append(schemas, prop.Elem.Schema)
+			schemas = append(schemas, prop.Elem.Schema.InlineSchemas()...)
+		}
+	}
+
+	return schemas
+}
+
+// Copy returns a copy of the schema named name. The nested blocks declared
+// inline in it are copied too, and renamed along, as the names of their
+// generated functions are derived from the name of the schema.
+func (ts TerraformSchema) Copy(name string) *TerraformSchema {
+	c := ts
+	c.Name = name
+	c.NameCamelCase = internal.ToIdentifier(name)
+	c.NameSnakeCase = internal.ToSnakeCase(name)
+	c.Properties = make(map[string]TerraformProperty, len(ts.Properties))
+
+	for attr, prop := range ts.Properties {
+		if prop.Elem != nil && prop.Elem.Schema != nil && prop.Elem.Schema.Inline {
+			elem := *prop.Elem
+			elem.Schema = elem.Schema.Copy(
+				name + strings.TrimPrefix(elem.Schema.Name, ts.Name))
+			prop.Elem = &elem
+		}
+
+		c.Properties[attr] = prop
+	}
+
+	c.Decisions = append([]Decision(nil), ts.Decisions...)
+
+	return &c
+}
+
+// IsRenamed returns true if the attribute name of the property differs from
+// the snake cased name of the API property.
+func (tp TerraformProperty) IsRenamed(name string) bool {
+	return tp.OriginalName != "" &&
+		internal.ToSnakeCase(tp.OriginalName) != name
+}
+
+// UsesRegexpPackage returns true if the SDKv2 code of a validator of the
+// schema, or of a nested block declared inline in it, refers to the regexp
+// package.
+func (ts TerraformSchema) UsesRegexpPackage() bool {
+	return ts.hasValidator(func(v Validator) bool {
+		switch v := v.(type) {
+		case Pattern:
+			return true
+		case Format:
+			return v.IsDate()
+		}
+
+		return false
+	})
+}
+
+// hasValidator returns true if a validator of the schema, or of a nested block
+// declared inline in it, matches.
+func (ts TerraformSchema) hasValidator(match func(Validator) bool) bool {
+	for _, prop := range ts.Properties {
+		for _, v := range prop.Validators {
+			if match(v) {
+				return true
+			}
+		}
+
+		if prop.Elem != nil && prop.Elem.Schema != nil &&
+			prop.Elem.Schema.Inline && prop.Elem.Schema.hasValidator(match) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsRequired returns true if the TerraformProperty is required.
 func (tp TerraformProperty) IsRequired() bool {
 	return tp.Required != nil && *tp.Required
@@ -111,23 +346,185 @@ func (tp TerraformProperty) IsComputed() bool {
 	return tp.Computed != nil && *tp.Computed
 }
 
-// Validate validates the TerraformSchema.
-func (ts TerraformSchema) Validate() []string {
-	var errs []string
+// IsCollection returns true if the TerraformProperty is a list or a set.
+func (tp TerraformProperty) IsCollection() bool {
+	return tp.Type == TypeList || tp.Type == TypeSet
+}
+
+// DefaultValue returns the default value of the TerraformProperty as a Go
+// literal of the type the SDK expects, or an empty string if there is none.
+func (tp TerraformProperty) DefaultValue() string {
+	if tp.Default == nil {
+		return ""
+	}
+
+	switch tp.Type {
+	case TypeString:
+		return strconv.Quote(fmt.Sprint(tp.Default))
+	case TypeBool:
+		if b, ok := tp.Default.(bool); ok {
+			return strconv.FormatBool(b)
+		}
+	case TypeInt:
+		if f, ok := toFloat64(tp.Default); ok {
+			return strconv.FormatInt(int64(f), 10)
+		}
+	case TypeFloat:
+		if f, ok := toFloat64(tp.Default); ok {
+			// Always include a decimal point, so the untyped constant becomes
+			// a float64 rather than an int.
+			lit := strconv.FormatFloat(f, 'f', -1, 64)
+			if !strings.Contains(lit, ".") {
+				lit += ".0"
+			}
+
+			return lit
+		}
+	}
+
+	return ""
+}
+
+// toFloat64 converts a decoded JSON or YAML number to a float64.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+
+	return 0, false
+}
+
+// ValidationError is a problem found while validating a TerraformSchema.
+type ValidationError struct {
+	// Property is the name of the offending property, with nested properties
+	// separated by dots. It is empty for problems with the schema itself.
+	Property string
+	// Pointer is the JSON pointer of the offending OpenAPI node, if known.
+	Pointer string
+	Message string
+}
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	if e.Property == "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("property '%s': %s", e.Property, e.Message)
+}
+
+var validAttributeName = regexp.MustCompile("^[a-z0-9_]+$")
+
+// Validate validates the TerraformSchema, including the checks the SDK
+// performs in InternalValidate, and returns one error per problem found.
+func (ts TerraformSchema) Validate() []ValidationError {
+	var errs []ValidationError
 
 	if ts.Name == "" {
-		errs = append(errs, "name is required")
+		errs = append(errs, ValidationError{
+			Pointer: ts.Pointer,
+			Message: "name is required",
+		})
 	}
 
 	if len(ts.Properties) == 0 {
-		errs = append(errs, "at least one property is required")
-	} else {
-		for name, prop := range ts.Properties {
-			errs = append(errs, prop.Validate()...)
-			if len(errs) > 0 {
-				errs = append(errs, "property: "+name)
+		errs = append(errs, ValidationError{
+			Pointer: ts.Pointer,
+			Message: "at least one property is required",
+		})
+	}
+
+	return append(errs, ts.validateProperties("")...)
+}
+
+// validateProperties validates every property of the TerraformSchema, and
+// recursively those of nested blocks. Property names are prefixed with prefix.
+func (ts TerraformSchema) validateProperties(prefix string) []ValidationError {
+	var errs []ValidationError
+
+	names := make([]string, 0, len(ts.Properties))
+	for name := range ts.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := ts.Properties[name]
+		path := prefix + name
+
+		add := func(msg string) {
+			errs = append(errs, ValidationError{
+				Property: path,
+				Pointer:  prop.Pointer,
+				Message:  msg,
+			})
+		}
+
+		if !validAttributeName.MatchString(name) {
+			add("name may only contain lowercase alphanumeric characters " +
+				"and underscores")
+		}
+
+		// Reserved names only apply to the top level of a resource.
+		if prefix == "" && IsReservedAttributeName(name) {
+			add(fmt.Sprintf("'%s' is a reserved attribute name", name))
+		}
+
+		if prefix == "" && name == IDAttributeName {
+			for _, msg := range prop.validateID() {
+				add(msg)
 			}
 		}
+
+		for _, msg := range prop.Validate() {
+			add(msg)
+		}
+
+		if prop.Elem != nil && prop.Elem.Schema != nil &&
+			prop.Elem.Schema.Inline {
+			errs = append(errs,
+				prop.Elem.Schema.validateProperties(path+".")...)
+		}
+	}
+
+	return errs
+}
+
+// IsReservedAttributeName returns true if Terraform reserves the given
+// attribute name.
+func IsReservedAttributeName(name string) bool {
+	for _, reserved := range ReservedAttributeNames {
+		if name == reserved {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateID validates a top-level property named id, which the SDK only
+// allows as a computed string.
+func (tp TerraformProperty) validateID() []string {
+	var errs []string
+
+	if tp.Type != TypeString {
+		errs = append(errs, `the "id" attribute must be of TypeString`)
+	}
+
+	if tp.IsRequired() {
+		errs = append(errs, `the "id" attribute cannot be marked Required`)
+	}
+
+	if !tp.IsComputed() {
+		errs = append(errs, `the "id" attribute must be marked Computed`)
 	}
 
 	return errs
@@ -145,14 +542,56 @@ func (tp TerraformProperty) Validate() []string {
 	opt := tp.IsOptional()
 	comp := tp.IsComputed()
 
+	// Optional and Computed may be combined for values the API defaults, but
+	// required values always come from the configuration.
 	if !req && !opt && !comp {
 		errs = append(
 			errs,
 			"At least one of Required, Optional, or Computed must be true")
-	} else if !internal.OnlyOneTrue(comp, opt, req) {
-		errs = append(
-			errs,
-			"Required, Optional, and Computed are mutually exclusive")
+	}
+
+	if req && opt {
+		errs = append(errs, "Required and Optional are mutually exclusive")
+	}
+
+	if req && comp {
+		errs = append(errs, "Required and Computed are mutually exclusive")
+	}
+
+	if tp.Default != nil {
+		if req {
+			errs = append(errs, "Default cannot be set with Required")
+		}
+
+		if comp {
+			errs = append(errs, "Default must be nil if Computed")
+		}
+
+		if tp.IsCollection() {
+			errs = append(errs, "Default is not valid for lists or sets")
+		}
+	}
+
+	if tp.IsCollection() {
+		if tp.Elem == nil {
+			errs = append(errs, "Elem must be set for lists and sets")
+		}
+
+		if tp.ValidateFunc != nil {
+			errs = append(errs, "ValidateFunc is not supported on lists or sets")
+		}
+	} else if tp.MaxItems != nil || tp.MinItems != nil {
+		errs = append(errs,
+			"MaxItems and MinItems are only supported on lists or sets")
+	}
+
+	if tp.Type == TypeMap && tp.Elem != nil && tp.Elem.Schema != nil {
+		errs = append(errs, "TypeMap with a nested block Elem is not supported")
+	}
+
+	if comp && !opt && !req && tp.ValidateFunc != nil {
+		errs = append(errs,
+			"ValidateFunc is not allowed on computed-only properties")
 	}
 
 	return errs
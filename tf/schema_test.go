@@ -7,8 +7,9 @@ import (
 	"github.com/stevenpaz/tf-schema-gen/tf"
 )
 
-// TestTerraformSchema_Validate tests the Validate method of TerraformSchema.
-func TestTerraformSchema_Validate(t *testing.T) {
+// TestTerraformProperty_Validate tests the Validate method of
+// TerraformProperty.
+func TestTerraformProperty_Validate(t *testing.T) {
 	t.Parallel()
 
 	type fields struct {
@@ -41,56 +42,55 @@ func TestTerraformSchema_Validate(t *testing.T) {
 			want: []string{"At least one of Required, Optional, or Computed must be true"},
 		},
 		{
-			name: "Required, Optional, and Computed are mutually exclusive",
+			name: "Required and Optional are mutually exclusive",
 			fields: fields{
 				Type:     "string",
 				Required: true,
 				Optional: true,
 			},
-			want: []string{
-				"Required, Optional, and Computed are mutually exclusive",
-			},
+			want: []string{"Required and Optional are mutually exclusive"},
 		},
 		{
-			name: "Required, Optional, and Computed are mutually exclusive",
+			name: "Required and Computed are mutually exclusive",
 			fields: fields{
 				Type:     "string",
 				Required: true,
 				Computed: true,
 			},
-			want: []string{
-				"Required, Optional, and Computed are mutually exclusive",
-			},
+			want: []string{"Required and Computed are mutually exclusive"},
 		},
 		{
-			name: "Required, Optional, and Computed are mutually exclusive",
+			name: "Optional and Computed may be combined",
 			fields: fields{
 				Type:     "string",
 				Optional: true,
 				Computed: true,
 			},
-			want: []string{"Required, Optional, and Computed are mutually exclusive"},
+			want: nil,
 		},
 		{
-			name: "Required, Optional, and Computed are mutually exclusive",
+			name: "Required, Optional and Computed",
 			fields: fields{
 				Type:     "string",
 				Required: true,
 				Optional: true,
 				Computed: true,
 			},
-			want: []string{"Required, Optional, and Computed are mutually exclusive"},
+			want: []string{
+				"Required and Optional are mutually exclusive",
+				"Required and Computed are mutually exclusive",
+			},
 		},
 		{
 			name: "Multiple errors",
 			fields: fields{
 				Type:     "",
-				Optional: true,
+				Required: true,
 				Computed: true,
 			},
 			want: []string{
 				"Type is required",
-				"Required, Optional, and Computed are mutually exclusive",
+				"Required and Computed are mutually exclusive",
 			},
 		},
 	}
@@ -99,20 +99,259 @@ func TestTerraformSchema_Validate(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			schema := tf.NewTerrformSchema("TestSchema")
-			schema.SetType(test.fields.Type)
-			schema.SetDescription(test.fields.Description)
-			schema.SetRequired(test.fields.Required)
-			schema.SetOptional(test.fields.Optional)
-			schema.SetComputed(test.fields.Computed)
-			schema.SetValidationFunc(test.fields.ValidationFunc)
+			prop := tf.NewTerraformProperty()
+			prop.Type = test.fields.Type
+			prop.SetDescription(test.fields.Description)
+			prop.SetRequired(test.fields.Required)
+			prop.SetOptional(test.fields.Optional)
+			prop.SetComputed(test.fields.Computed)
+			prop.SetValidateFunc(test.fields.ValidationFunc)
 
-			if got := schema.Validate(); !reflect.DeepEqual(got, test.want) {
+			if got := prop.Validate(); !reflect.DeepEqual(got, test.want) {
 				t.Errorf(
-					"TerraformSchema.Validate() = %v, want %v",
+					"TerraformProperty.Validate() = %v, want %v",
 					got,
 					test.want)
 			}
 		})
 	}
 }
+
+// TestTerraformProperty_ValidateSDKRules tests the checks the SDK performs in
+// InternalValidate.
+func TestTerraformProperty_ValidateSDKRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		setup func(prop *tf.TerraformProperty)
+		want  []string
+	}{
+		{
+			name: "list without Elem",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeList
+				prop.SetOptional(true)
+			},
+			want: []string{"Elem must be set for lists and sets"},
+		},
+		{
+			name: "set with Elem",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeSet
+				prop.SetOptional(true)
+				prop.SetElemType(tf.TypeString)
+				prop.SetMaxItems(3)
+			},
+			want: nil,
+		},
+		{
+			name: "Default with Required",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeString
+				prop.SetRequired(true)
+				prop.SetDefault("a")
+			},
+			want: []string{"Default cannot be set with Required"},
+		},
+		{
+			name: "MaxItems on a primitive",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeString
+				prop.SetOptional(true)
+				prop.SetMaxItems(1)
+			},
+			want: []string{
+				"MaxItems and MinItems are only supported on lists or sets",
+			},
+		},
+		{
+			name: "ValidateFunc on a computed-only property",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeString
+				prop.SetComputed(true)
+				prop.SetValidateFunc(tf.ValidateFuncRFC3339Time)
+			},
+			want: []string{
+				"ValidateFunc is not allowed on computed-only properties",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			prop := tf.NewTerraformProperty()
+			test.setup(prop)
+
+			if got := prop.Validate(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf(
+					"TerraformProperty.Validate() = %v, want %v",
+					got,
+					test.want)
+			}
+		})
+	}
+}
+
+// TestTerraformSchema_Validate tests the Validate method of TerraformSchema.
+func TestTerraformSchema_Validate(t *testing.T) {
+	t.Parallel()
+
+	valid := tf.NewTerraformProperty()
+	valid.Type = tf.TypeString
+	valid.SetOptional(true)
+
+	invalid := tf.NewTerraformProperty()
+	invalid.Type = tf.TypeString
+	invalid.Pointer = "#/components/schemas/Test/properties/bad"
+
+	nested := tf.NewTerrformSchema("TestNested", nil)
+	nested.Inline = true
+	nested.AddProp("Inner", valid)
+
+	block := tf.NewTerraformProperty()
+	block.Type = tf.TypeList
+	block.SetOptional(true)
+	block.SetElemSchema(nested)
+
+	schema := tf.NewTerrformSchema("Test", nil)
+	schema.AddProp("a_bad", invalid)
+	schema.AddProp("b_good", valid)
+	schema.AddProp("block", block)
+	schema.AddProp("count", valid)
+	schema.AddProp("z_good", valid)
+
+	want := []tf.ValidationError{
+		{
+			Property: "a_bad",
+			Pointer:  "#/components/schemas/Test/properties/bad",
+			Message:  "At least one of Required, Optional, or Computed must be true",
+		},
+		{
+			Property: "block.Inner",
+			Message:  "name may only contain lowercase alphanumeric characters and underscores",
+		},
+		{
+			Property: "count",
+			Message:  "'count' is a reserved attribute name",
+		},
+	}
+
+	if got := schema.Validate(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TerraformSchema.Validate() = %v, want %v", got, want)
+	}
+
+	empty := tf.NewTerrformSchema("Empty", nil)
+	if got := empty.Validate(); len(got) != 1 {
+		t.Errorf("TerraformSchema.Validate() = %v, want one error", got)
+	}
+}
+
+// TestTerraformSchema_ValidateID tests the rules the SDK has for the id
+// attribute.
+func TestTerraformSchema_ValidateID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		setup func(prop *tf.TerraformProperty)
+		want  []string
+	}{
+		{
+			name: "computed string",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeString
+				prop.SetComputed(true)
+			},
+			want: nil,
+		},
+		{
+			name: "optional and computed string",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeString
+				prop.SetOptional(true)
+				prop.SetComputed(true)
+			},
+			want: nil,
+		},
+		{
+			name: "optional string",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeString
+				prop.SetOptional(true)
+			},
+			want: []string{`the "id" attribute must be marked Computed`},
+		},
+		{
+			name: "required int",
+			setup: func(prop *tf.TerraformProperty) {
+				prop.Type = tf.TypeInt
+				prop.SetRequired(true)
+			},
+			want: []string{
+				`the "id" attribute must be of TypeString`,
+				`the "id" attribute cannot be marked Required`,
+				`the "id" attribute must be marked Computed`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			prop := tf.NewTerraformProperty()
+			test.setup(prop)
+
+			schema := tf.NewTerrformSchema("Test", nil)
+			schema.AddProp(tf.IDAttributeName, prop)
+
+			var got []string
+			for _, e := range schema.Validate() {
+				got = append(got, e.Message)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("TerraformSchema.Validate() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestTerraformProperty_DefaultValue tests rendering defaults as Go literals.
+func TestTerraformProperty_DefaultValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		typ   string
+		value interface{}
+		want  string
+	}{
+		{name: "string", typ: tf.TypeString, value: `say "hi"`, want: `"say \"hi\""`},
+		{name: "bool", typ: tf.TypeBool, value: true, want: "true"},
+		{name: "int", typ: tf.TypeInt, value: float64(3), want: "3"},
+		{name: "whole float", typ: tf.TypeFloat, value: float64(3), want: "3.0"},
+		{name: "float", typ: tf.TypeFloat, value: 2.5, want: "2.5"},
+		{name: "none", typ: tf.TypeString, value: nil, want: ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			prop := tf.NewTerraformProperty()
+			prop.Type = test.typ
+			prop.SetDefault(test.value)
+
+			if got := prop.DefaultValue(); got != test.want {
+				t.Errorf("DefaultValue() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
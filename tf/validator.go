@@ -0,0 +1,500 @@
+package tf
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stevenpaz/tf-schema-gen/internal"
+)
+
+// Validator is a rule the values of a property must follow. It renders the
+// rule for every backend the generator targets.
+type Validator interface {
+	// SDKv2 returns the SDKv2 validation functions that check the rule.
+	SDKv2() []string
+	// Framework returns the terraform-plugin-framework validators that check
+	// the rule. Rules that no framework validator checks are left out.
+	Framework() []string
+	// Describe returns the sentences that describe the rule in
+	// documentation.
+	Describe() []string
+	// Accepts returns true if the rule accepts a value, decoded from JSON.
+	// Values of the wrong type are not accepted.
+	Accepts(v interface{}) bool
+}
+
+// IntRange limits integer values to a range.
+type IntRange struct {
+	// Min and Max are the bounds of the range, or nil if it is unbounded.
+	Min *int64
+	Max *int64
+	// ExclusiveMin and ExclusiveMax exclude the bounds from the range.
+	ExclusiveMin bool
+	ExclusiveMax bool
+}
+
+// bounds returns the inclusive bounds of the range.
+func (r IntRange) bounds() (min, max *int64) {
+	if r.Min != nil {
+		n := *r.Min
+		if r.ExclusiveMin {
+			n++
+		}
+
+		min = &n
+	}
+
+	if r.Max != nil {
+		n := *r.Max
+		if r.ExclusiveMax {
+			n--
+		}
+
+		max = &n
+	}
+
+	return min, max
+}
+
+// SDKv2 implements Validator.
+func (r IntRange) SDKv2() []string {
+	var f []string
+
+	min, max := r.bounds()
+
+	if min != nil {
+		f = append(f, fmt.Sprintf(ValidateFuncIntAtLeast, *min))
+	}
+
+	if max != nil {
+		f = append(f, fmt.Sprintf(ValidateFuncIntAtMost, *max))
+	}
+
+	return f
+}
+
+// Framework implements Validator.
+func (r IntRange) Framework() []string {
+	var f []string
+
+	min, max := r.bounds()
+
+	if min != nil {
+		f = append(f, fmt.Sprintf("int64validator.AtLeast(%d)", *min))
+	}
+
+	if max != nil {
+		f = append(f, fmt.Sprintf("int64validator.AtMost(%d)", *max))
+	}
+
+	return f
+}
+
+// Describe implements Validator.
+func (r IntRange) Describe() []string {
+	var notes []string
+
+	min, max := r.bounds()
+
+	if min != nil {
+		notes = append(notes, fmt.Sprintf("Must be at least %d.", *min))
+	}
+
+	if max != nil {
+		notes = append(notes, fmt.Sprintf("Must be at most %d.", *max))
+	}
+
+	return notes
+}
+
+// Accepts implements Validator.
+func (r IntRange) Accepts(v interface{}) bool {
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) {
+		return false
+	}
+
+	min, max := r.bounds()
+
+	return (min == nil || int64(f) >= *min) && (max == nil || int64(f) <= *max)
+}
+
+// FloatRange limits number values to a range.
+type FloatRange struct {
+	// Min and Max are the bounds of the range, or nil if it is unbounded.
+	Min *float64
+	Max *float64
+	// ExclusiveMin and ExclusiveMax exclude the bounds from the range.
+	ExclusiveMin bool
+	ExclusiveMax bool
+}
+
+// bounds returns the inclusive bounds of the range. Exclusive bounds become
+// the adjacent float inside the range, as neither the SDK nor the framework
+// has validators for them.
+func (r FloatRange) bounds() (min, max *float64) {
+	if r.Min != nil {
+		v := *r.Min
+		if r.ExclusiveMin {
+			v = math.Nextafter(v, math.Inf(1))
+		}
+
+		min = &v
+	}
+
+	if r.Max != nil {
+		v := *r.Max
+		if r.ExclusiveMax {
+			v = math.Nextafter(v, math.Inf(-1))
+		}
+
+		max = &v
+	}
+
+	return min, max
+}
+
+// SDKv2 implements Validator.
+func (r FloatRange) SDKv2() []string {
+	var f []string
+
+	min, max := r.bounds()
+
+	if min != nil {
+		f = append(f, fmt.Sprintf(ValidateFuncFloatAtLeast, floatLiteral(*min)))
+	}
+
+	if max != nil {
+		f = append(f, fmt.Sprintf(ValidateFuncFloatAtMost, floatLiteral(*max)))
+	}
+
+	return f
+}
+
+// Framework implements Validator.
+func (r FloatRange) Framework() []string {
+	var f []string
+
+	min, max := r.bounds()
+
+	if min != nil {
+		f = append(f, fmt.Sprintf("float64validator.AtLeast(%s)",
+			floatLiteral(*min)))
+	}
+
+	if max != nil {
+		f = append(f, fmt.Sprintf("float64validator.AtMost(%s)",
+			floatLiteral(*max)))
+	}
+
+	return f
+}
+
+// Describe implements Validator.
+func (r FloatRange) Describe() []string {
+	var notes []string
+
+	switch {
+	case r.Min != nil && r.ExclusiveMin:
+		notes = append(notes, "Must be greater than "+formatFloat(*r.Min)+".")
+	case r.Min != nil:
+		notes = append(notes, "Must be at least "+formatFloat(*r.Min)+".")
+	}
+
+	switch {
+	case r.Max != nil && r.ExclusiveMax:
+		notes = append(notes, "Must be less than "+formatFloat(*r.Max)+".")
+	case r.Max != nil:
+		notes = append(notes, "Must be at most "+formatFloat(*r.Max)+".")
+	}
+
+	return notes
+}
+
+// Accepts implements Validator.
+func (r FloatRange) Accepts(v interface{}) bool {
+	f, ok := v.(float64)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case r.Min != nil && r.ExclusiveMin && f <= *r.Min,
+		r.Min != nil && f < *r.Min,
+		r.Max != nil && r.ExclusiveMax && f >= *r.Max,
+		r.Max != nil && f > *r.Max:
+		return false
+	}
+
+	return true
+}
+
+// formatFloat formats a float in the shortest form that represents it.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// floatLiteral formats a float as the shortest Go literal that represents it,
+// using an exponent for very large or small values.
+func floatLiteral(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Pattern limits string values to those that match a regular expression.
+type Pattern struct {
+	// Regex is the regular expression, in the syntax of the regexp package.
+	Regex string
+}
+
+// SDKv2 implements Validator.
+func (p Pattern) SDKv2() []string {
+	return []string{fmt.Sprintf(ValidateFuncStringMatch,
+		quoteRegexp(p.Regex))}
+}
+
+// Framework implements Validator.
+func (p Pattern) Framework() []string {
+	return []string{fmt.Sprintf(
+		`stringvalidator.RegexMatches(regexp.MustCompile(%s), "")`,
+		quoteRegexp(p.Regex))}
+}
+
+// Describe implements Validator.
+func (p Pattern) Describe() []string {
+	return []string{
+		fmt.Sprintf("Must match the regular expression `%s`.", p.Regex),
+	}
+}
+
+// Accepts implements Validator.
+func (p Pattern) Accepts(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+
+	matched, err := regexp.MatchString(p.Regex, s)
+
+	return err == nil && matched
+}
+
+// quoteRegexp returns a regular expression as a Go string literal, which is a
+// raw string if it can be, so it reads like the expression.
+func quoteRegexp(re string) string {
+	if strconv.CanBackquote(re) {
+		return "`" + re + "`"
+	}
+
+	return strconv.Quote(re)
+}
+
+// OneOf limits values to a fixed set. The values are all strings or all ints.
+type OneOf struct {
+	Values []interface{}
+}
+
+// isString returns true if the values are strings.
+func (o OneOf) isString() bool {
+	if len(o.Values) == 0 {
+		return false
+	}
+
+	_, ok := o.Values[0].(string)
+
+	return ok
+}
+
+// literals returns the values as Go literals.
+func (o OneOf) literals() string {
+	values := make([]string, 0, len(o.Values))
+
+	for _, v := range o.Values {
+		if s, ok := v.(string); ok {
+			values = append(values, internal.QuoteGoString(s))
+		} else {
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// SDKv2 implements Validator.
+func (o OneOf) SDKv2() []string {
+	if o.isString() {
+		return []string{fmt.Sprintf(ValidateFuncStringInSlice, o.literals())}
+	}
+
+	return []string{fmt.Sprintf(ValidateFuncIntInSlice, o.literals())}
+}
+
+// Framework implements Validator.
+func (o OneOf) Framework() []string {
+	if o.isString() {
+		return []string{fmt.Sprintf("stringvalidator.OneOf(%s)", o.literals())}
+	}
+
+	return []string{fmt.Sprintf("int64validator.OneOf(%s)", o.literals())}
+}
+
+// Describe implements Validator.
+func (o OneOf) Describe() []string {
+	values := make([]string, 0, len(o.Values))
+	for _, v := range o.Values {
+		values = append(values, fmt.Sprintf("`%v`", v))
+	}
+
+	return []string{
+		fmt.Sprintf("Must be one of %s.", strings.Join(values, ", ")),
+	}
+}
+
+// Accepts implements Validator.
+func (o OneOf) Accepts(v interface{}) bool {
+	for _, value := range o.Values {
+		if fmt.Sprint(value) == fmt.Sprint(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Format limits string values to a format, like date-time.
+type Format struct {
+	// Name is the name of the format in OpenAPI.
+	Name string
+}
+
+// dateRegexp matches the full-date of RFC 3339, e.g. 2006-01-02.
+const dateRegexp = `^\d{4}-\d{2}-\d{2}$`
+
+// timestampRegexp matches the date-time of RFC 3339, e.g.
+// 2006-01-02T15:04:05Z.
+const timestampRegexp = `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`
+
+// IsTimestamp returns true if the format is an RFC 3339 timestamp.
+func (f Format) IsTimestamp() bool {
+	return f.Name == "date-time"
+}
+
+// IsDate returns true if the format is an RFC 3339 full-date, which has no
+// time.
+func (f Format) IsDate() bool {
+	return f.Name == "date"
+}
+
+// IsChecked returns true if values are validated against the format.
+func (f Format) IsChecked() bool {
+	return f.IsTimestamp() || f.IsDate()
+}
+
+// SDKv2 implements Validator. The SDK has no validator for dates, so they are
+// matched against a regular expression.
+func (f Format) SDKv2() []string {
+	switch {
+	case f.IsTimestamp():
+		return []string{ValidateFuncRFC3339Time}
+	case f.IsDate():
+		return []string{fmt.Sprintf(ValidateFuncStringMatch,
+			quoteRegexp(dateRegexp))}
+	}
+
+	return nil
+}
+
+// Framework implements Validator. The framework has no validators for
+// formats, so values are matched against a regular expression.
+func (f Format) Framework() []string {
+	var re string
+
+	switch {
+	case f.IsTimestamp():
+		re = timestampRegexp
+	case f.IsDate():
+		re = dateRegexp
+	default:
+		return nil
+	}
+
+	return []string{fmt.Sprintf(
+		`stringvalidator.RegexMatches(regexp.MustCompile(%s), "")`,
+		quoteRegexp(re))}
+}
+
+// Describe implements Validator.
+func (f Format) Describe() []string {
+	switch {
+	case f.IsTimestamp():
+		return []string{"Must be an RFC 3339 timestamp."}
+	case f.IsDate():
+		return []string{"Must be a date in the form `YYYY-MM-DD`."}
+	}
+
+	return nil
+}
+
+// Accepts implements Validator.
+func (f Format) Accepts(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case f.IsTimestamp():
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case f.IsDate():
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	}
+
+	return true
+}
+
+// SDKv2ValidateFunc returns the SDKv2 ValidateDiagFunc that checks every
+// validator, or an empty string if there are none.
+func SDKv2ValidateFunc(validators []Validator) string {
+	var f []string
+	for _, v := range validators {
+		f = append(f, v.SDKv2()...)
+	}
+
+	switch len(f) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("validation.ToDiagFunc(%s)", f[0])
+	default:
+		return fmt.Sprintf("validation.ToDiagFunc(validation.All(%s))",
+			strings.Join(f, ","))
+	}
+}
+
+// DescribeValidators returns the sentences that describe every validator in
+// documentation.
+func DescribeValidators(validators []Validator) []string {
+	var notes []string
+	for _, v := range validators {
+		notes = append(notes, v.Describe()...)
+	}
+
+	return notes
+}
+
+// ValidatorsAccept returns true if every validator accepts a value, decoded
+// from JSON.
+func ValidatorsAccept(validators []Validator, v interface{}) bool {
+	for _, validator := range validators {
+		if !validator.Accepts(v) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,205 @@
+package tf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stevenpaz/tf-schema-gen/tf"
+)
+
+// TestValidator tests rendering validators for every backend.
+func TestValidator(t *testing.T) {
+	t.Parallel()
+
+	one, five := int64(1), int64(5)
+	half := 0.5
+
+	tests := []struct {
+		name          string
+		validator     tf.Validator
+		wantSDKv2     []string
+		wantFramework []string
+		wantDescribe  []string
+	}{
+		{
+			name:          "int range",
+			validator:     tf.IntRange{Min: &one, Max: &five},
+			wantSDKv2:     []string{"validation.IntAtLeast(1)", "validation.IntAtMost(5)"},
+			wantFramework: []string{"int64validator.AtLeast(1)", "int64validator.AtMost(5)"},
+			wantDescribe:  []string{"Must be at least 1.", "Must be at most 5."},
+		},
+		{
+			name: "exclusive int range",
+			validator: tf.IntRange{
+				Min: &one, Max: &five, ExclusiveMin: true, ExclusiveMax: true,
+			},
+			wantSDKv2:     []string{"validation.IntAtLeast(2)", "validation.IntAtMost(4)"},
+			wantFramework: []string{"int64validator.AtLeast(2)", "int64validator.AtMost(4)"},
+			wantDescribe:  []string{"Must be at least 2.", "Must be at most 4."},
+		},
+		{
+			name:          "float range",
+			validator:     tf.FloatRange{Max: &half},
+			wantSDKv2:     []string{"validation.FloatAtMost(0.5)"},
+			wantFramework: []string{"float64validator.AtMost(0.5)"},
+			wantDescribe:  []string{"Must be at most 0.5."},
+		},
+		{
+			name:          "exclusive float range",
+			validator:     tf.FloatRange{Min: &half, ExclusiveMin: true},
+			wantSDKv2:     []string{"validation.FloatAtLeast(0.5000000000000001)"},
+			wantFramework: []string{"float64validator.AtLeast(0.5000000000000001)"},
+			wantDescribe:  []string{"Must be greater than 0.5."},
+		},
+		{
+			name:      "pattern",
+			validator: tf.Pattern{Regex: `^\d+$`},
+			wantSDKv2: []string{
+				"validation.StringMatch(regexp.MustCompile(`^\\d+$`), \"\")",
+			},
+			wantFramework: []string{
+				"stringvalidator.RegexMatches(regexp.MustCompile(`^\\d+$`), \"\")",
+			},
+			wantDescribe: []string{"Must match the regular expression `^\\d+$`."},
+		},
+		{
+			name:          "string enum",
+			validator:     tf.OneOf{Values: []interface{}{"a", "b"}},
+			wantSDKv2:     []string{`validation.StringInSlice([]string{"a", "b"}, false)`},
+			wantFramework: []string{`stringvalidator.OneOf("a", "b")`},
+			wantDescribe:  []string{"Must be one of `a`, `b`."},
+		},
+		{
+			name:          "int enum",
+			validator:     tf.OneOf{Values: []interface{}{int64(1), int64(2)}},
+			wantSDKv2:     []string{"validation.IntInSlice([]int{1, 2})"},
+			wantFramework: []string{"int64validator.OneOf(1, 2)"},
+			wantDescribe:  []string{"Must be one of `1`, `2`."},
+		},
+		{
+			name:      "timestamp format",
+			validator: tf.Format{Name: "date-time"},
+			wantSDKv2: []string{tf.ValidateFuncRFC3339Time},
+			wantFramework: []string{
+				"stringvalidator.RegexMatches(regexp.MustCompile(" +
+					"`^\\d{4}-\\d{2}-\\d{2}T\\d{2}:\\d{2}:\\d{2}(\\.\\d+)?(Z|[+-]\\d{2}:\\d{2})$`), \"\")",
+			},
+			wantDescribe: []string{"Must be an RFC 3339 timestamp."},
+		},
+		{
+			name:      "date format",
+			validator: tf.Format{Name: "date"},
+			wantSDKv2: []string{
+				"validation.StringMatch(regexp.MustCompile(`^\\d{4}-\\d{2}-\\d{2}$`), \"\")",
+			},
+			wantFramework: []string{
+				"stringvalidator.RegexMatches(regexp.MustCompile(`^\\d{4}-\\d{2}-\\d{2}$`), \"\")",
+			},
+			wantDescribe: []string{"Must be a date in the form `YYYY-MM-DD`."},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := test.validator.SDKv2(); !reflect.DeepEqual(got, test.wantSDKv2) {
+				t.Errorf("SDKv2() = %q, want %q", got, test.wantSDKv2)
+			}
+
+			got := test.validator.Framework()
+			if !reflect.DeepEqual(got, test.wantFramework) {
+				t.Errorf("Framework() = %q, want %q", got, test.wantFramework)
+			}
+
+			got = test.validator.Describe()
+			if !reflect.DeepEqual(got, test.wantDescribe) {
+				t.Errorf("Describe() = %q, want %q", got, test.wantDescribe)
+			}
+		})
+	}
+}
+
+// TestSDKv2ValidateFunc tests combining validators into one ValidateDiagFunc.
+func TestSDKv2ValidateFunc(t *testing.T) {
+	t.Parallel()
+
+	one := int64(1)
+
+	tests := []struct {
+		name       string
+		validators []tf.Validator
+		want       string
+	}{
+		{name: "none", want: ""},
+		{
+			name:       "one",
+			validators: []tf.Validator{tf.IntRange{Min: &one}},
+			want:       "validation.ToDiagFunc(validation.IntAtLeast(1))",
+		},
+		{
+			name: "many",
+			validators: []tf.Validator{
+				tf.Format{Name: "date"},
+				tf.OneOf{Values: []interface{}{"a"}},
+			},
+			want: "validation.ToDiagFunc(validation.All(" +
+				"validation.StringMatch(regexp.MustCompile(`^\\d{4}-\\d{2}-\\d{2}$`), \"\")," +
+				`validation.StringInSlice([]string{"a"}, false)))`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tf.SDKv2ValidateFunc(test.validators); got != test.want {
+				t.Errorf("SDKv2ValidateFunc() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestValidator_Accepts tests checking values against validators.
+func TestValidator_Accepts(t *testing.T) {
+	t.Parallel()
+
+	zero, five := int64(0), int64(5)
+	zeroF, half := 0.0, 0.5
+
+	tests := []struct {
+		name      string
+		validator tf.Validator
+		value     interface{}
+		want      bool
+	}{
+		{name: "int in range", validator: tf.IntRange{Min: &zero, Max: &five}, value: 5.0, want: true},
+		{name: "int below exclusive min", validator: tf.IntRange{Min: &zero, ExclusiveMin: true}, value: 0.0, want: false},
+		{name: "int not whole", validator: tf.IntRange{Min: &zero}, value: 0.5, want: false},
+		{name: "float above min", validator: tf.FloatRange{Min: &zeroF, ExclusiveMin: true}, value: 0.1, want: true},
+		{name: "float at exclusive max", validator: tf.FloatRange{Max: &half, ExclusiveMax: true}, value: 0.5, want: false},
+		{name: "float at max", validator: tf.FloatRange{Max: &half}, value: 0.5, want: true},
+		{name: "pattern match", validator: tf.Pattern{Regex: `^[a-z]+$`}, value: "abc", want: true},
+		{name: "pattern mismatch", validator: tf.Pattern{Regex: `^[a-z]+$`}, value: "abc-updated", want: false},
+		{name: "enum value", validator: tf.OneOf{Values: []interface{}{int64(1), int64(2)}}, value: 2.0, want: true},
+		{name: "not an enum value", validator: tf.OneOf{Values: []interface{}{"a"}}, value: "b", want: false},
+		{name: "timestamp", validator: tf.Format{Name: "date-time"}, value: "2006-01-02T15:04:05Z", want: true},
+		{name: "not a timestamp", validator: tf.Format{Name: "date-time"}, value: "example", want: false},
+		{name: "date", validator: tf.Format{Name: "date"}, value: "2006-01-02", want: true},
+		{name: "timestamp as date", validator: tf.Format{Name: "date"}, value: "2006-01-02T15:04:05Z", want: false},
+		{name: "wrong type", validator: tf.Pattern{Regex: `.*`}, value: 1.0, want: false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := test.validator.Accepts(test.value); got != test.want {
+				t.Errorf("Accepts(%v) = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}